@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/chat"
+	"github.com/andreivisan/quantum_cli/pkg/rag"
+	"github.com/charmbracelet/glamour"
+	"github.com/spf13/cobra"
+)
+
+var (
+	askRaw      bool
+	askThinking bool
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask [question]",
+	Short: "Ask a single question and print the answer",
+	Long: `Ask the AI assistant a single question without starting the chat UI.
+
+Anything piped to stdin is sent along with the question as context, so
+qcli fits into scripts and pipelines. The command exits with a non-zero
+status if the backend reports an error.
+
+When stdout is a terminal the answer is rendered as Markdown once it is
+complete. With --raw, or when stdout is redirected, the answer is streamed
+as plain text while it is generated.
+
+Usage:
+  qcli ask "How do I reverse a slice in Go?"
+  git diff | qcli ask "review this"
+  cat main.go | qcli ask --raw "write tests for this" > main_test.go
+  qcli ask --rag qcli "where are sessions saved?"
+  qcli ask --profile reviewer "review this" < main.go`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		question := strings.Join(args, " ")
+		if !isTerminal(os.Stdin) {
+			piped, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("error reading stdin: %w", err)
+			}
+			question = withContext(question, string(piped))
+		}
+		if strings.TrimSpace(question) == "" {
+			return errors.New("nothing to ask, pass a question or pipe input to stdin")
+		}
+
+		profile, err := activeProfile()
+		if err != nil {
+			return err
+		}
+		provider, err := newProvider()
+		if err != nil {
+			return err
+		}
+		if provider, err = withRetrievers(provider); err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		request := ai.ChatRequest{
+			Model:    cfg.Model,
+			Messages: []ai.Message{{Role: ai.RoleUser, Content: question}},
+			System:   profile.SystemPrompt,
+			Options:  requestOptions(profile),
+		}
+		raw := askRaw || !isTerminal(os.Stdout)
+		return ask(ctx, provider, request, raw)
+	},
+}
+
+// ask streams the answer to request to stdout, or renders it as Markdown
+// once complete when raw is false.
+func ask(ctx context.Context, provider ai.Provider, request ai.ChatRequest, raw bool) error {
+	events := make(chan ai.Event)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(events)
+		errChan <- provider.Chat(ctx, request, events)
+	}()
+
+	var answer strings.Builder
+	var serverErr error
+	for event := range events {
+		switch event.Type {
+		case ai.EventThinking:
+			if askThinking {
+				fmt.Fprint(os.Stderr, event.Text)
+			}
+		case ai.EventAnswer:
+			if raw {
+				fmt.Print(event.Text)
+			} else {
+				answer.WriteString(event.Text)
+			}
+		case ai.EventDone:
+			// The sources of --rag and memory follow the answer
+			if len(event.Sources) == 0 {
+				break
+			}
+			if raw {
+				fmt.Print(rag.Footer(event.Sources))
+			} else {
+				answer.WriteString(rag.Footer(event.Sources))
+			}
+		case ai.EventError:
+			serverErr = fmt.Errorf("error from AI server: %s", event.Text)
+		}
+	}
+	if err := <-errChan; err != nil {
+		return fmt.Errorf("error communicating with AI server: %w", err)
+	}
+	if serverErr != nil {
+		return serverErr
+	}
+
+	if raw {
+		fmt.Println()
+		return nil
+	}
+	return printMarkdown(answer.String())
+}
+
+func printMarkdown(markdown string) error {
+	renderer, err := glamour.NewTermRenderer(chat.ThemeOption(cfg.Theme), glamour.WithWordWrap(100))
+	if err != nil {
+		return fmt.Errorf("error creating renderer: %w", err)
+	}
+	rendered, err := renderer.Render(markdown)
+	if err != nil {
+		return fmt.Errorf("error rendering answer: %w", err)
+	}
+	fmt.Print(rendered)
+	return nil
+}
+
+// withContext appends piped input to question as a fenced block.
+func withContext(question string, piped string) string {
+	piped = strings.TrimRight(piped, "\n")
+	if piped == "" {
+		return question
+	}
+	if strings.TrimSpace(question) == "" {
+		return piped
+	}
+	fence := "```"
+	for strings.Contains(piped, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("%s\n\n%s\n%s\n%s", question, fence, piped, fence)
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	askCmd.Flags().BoolVar(&askRaw, "raw", false, "stream plain text instead of rendering Markdown")
+	askCmd.Flags().BoolVar(&askThinking, "thinking", false, "print the model's reasoning to stderr")
+	addRAGFlags(askCmd)
+	addProfileFlag(askCmd)
+	rootCmd.AddCommand(askCmd)
+}
@@ -4,14 +4,27 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
 
 	"github.com/andreivisan/quantum_cli/pkg/ai"
 	"github.com/andreivisan/quantum_cli/pkg/chat"
+	"github.com/andreivisan/quantum_cli/pkg/config"
+	"github.com/andreivisan/quantum_cli/pkg/session"
+	"github.com/andreivisan/quantum_cli/pkg/tool"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
+var (
+	resumeID     string
+	continueLast bool
+)
+
 // chatCmd represents the chat command
 var chatCmd = &cobra.Command{
 	Use:   "chat",
@@ -22,48 +35,275 @@ The chat interface provides:
 • A clean terminal UI
 • Real-time streaming responses
 • Support for multi-line input
+• Follow-up questions with the conversation history as context
+• Conversations saved automatically, see 'qcli sessions'
 • Clear separation between user and AI messages
 
 Usage:
   qcli chat
+  qcli chat --continue
+  qcli chat --resume 20241205-101500-3fa2c91e
+  qcli chat --provider ollama --model qwq
+  qcli chat --provider openai --server http://localhost:8080/v1 --model qwen2.5-coder
+  qcli chat --rag qcli
+  qcli chat --profile reviewer
+
+The openai provider reads its API key from OPENAI_API_KEY.
 
+With --rag, each question is sent with the most relevant chunks of an
+index built by 'qcli index', and the answer cites them as file:line.
+With --memory, or /memory on, relevant exchanges of earlier sessions are
+recalled too, see 'qcli memory'.
+
+Type /export [md|json|html] [path] to save the conversation to a file.
+Type /profile <name> to answer with another profile, see 'qcli profiles'.
+Type /attach <path|glob|dir> or mention @path in a message to send files
+along with it; ignored, binary and oversized files are skipped. /detach
+drops them again.
+Press Ctrl+O to switch the model for the next messages.
+Press Ctrl+T to show or hide the reasoning of an answer, Alt+Up and
+Alt+Down to pick an earlier answer.
+Press Esc while an answer is streaming to stop it.
+Press Ctrl+R to retry a message whose answer failed.
 Press Ctrl+C to exit the chat session.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		userInputChan := make(chan string)
-		aiOutputChan := make(chan string)
-
-		// Start goroutine to handle communication with Python server
-		go func() {
-			defer close(aiOutputChan)
-			client := ai.NewClient("http://localhost:8000")
-
-			for message := range userInputChan {
-				if err := client.Chat(message, aiOutputChan); err != nil {
-					fmt.Printf("Error communicating with AI server: %v\n", err)
-					continue
+		if err := prepareChat(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := runTool(newChatModel); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// prepareChat does what may prompt or print before the chat owns the
+// screen: it pulls the embed model and syncs the memory when memory is on,
+// and reports the sessions --continue could not read. newChatModel does
+// neither, so the menu can open the chat too.
+func prepareChat() error {
+	if cfg.Memory {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		_, err := loadMemory(ctx)
+		return err
+	}
+	if continueLast {
+		store, err := openSessionStore()
+		if err != nil {
+			return err
+		}
+		_, err = store.List()
+		return warnSkipped(err)
+	}
+	return nil
+}
+
+// newChatModel opens the chat session and starts the goroutine that talks
+// to the AI backend for the chat screen.
+func newChatModel() (tea.Model, error) {
+	userInputChan := make(chan ai.ChatRequest)
+	aiOutputChan := make(chan ai.Event)
+	stopChan := make(chan struct{}, 1)
+
+	if _, err := activeProfile(); err != nil {
+		return nil, err
+	}
+	store, chatSession, err := openChatSession()
+	if err != nil {
+		return nil, err
+	}
+
+	recall, err := memoryRetriever(chatSession.ID)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := newProvider()
+	if err == nil {
+		provider, err = withRetrievers(provider, recall)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Start goroutine to handle communication with the AI backend
+	go func() {
+		defer close(aiOutputChan)
+
+		for request := range userInputChan {
+			// Drop a stop request left over from a reply that already ended
+			select {
+			case <-stopChan:
+			default:
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-stopChan:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			events := make(chan ai.Event)
+			errChan := make(chan error, 1)
+			go func() {
+				defer close(events)
+				errChan <- provider.Chat(ctx, request, events)
+			}()
+
+			// The UI keeps listening until the reply's done or error
+			// event, so exactly one of them is sent last, even when the
+			// reply was stopped. Printing is not an option while Bubble
+			// Tea owns the screen, so errors travel the same way.
+			end := ai.Event{Type: ai.EventDone}
+			for event := range events {
+				switch event.Type {
+				case ai.EventDone, ai.EventError:
+					end = event
+				default:
+					if ctx.Err() != nil {
+						continue
+					}
+					select {
+					case aiOutputChan <- event:
+					case <-ctx.Done():
+					}
 				}
 			}
-		}()
+			if err := <-errChan; err != nil && !errors.Is(err, context.Canceled) {
+				end = ai.Event{
+					Type: ai.EventError,
+					Text: fmt.Sprintf("error communicating with AI server: %v", err),
+				}
+			}
+			aiOutputChan <- end
+			cancel()
+		}
+	}()
 
-		p := tea.NewProgram(
-			chat.New(userInputChan, aiOutputChan),
-			tea.WithAltScreen(),
-		)
+	chatModel := chat.New(userInputChan, aiOutputChan, stopChan)
+	chatModel.SetTheme(cfg.Theme)
+	if cfg.Provider != "quantum" {
+		// A resumed session keeps its model unless one was configured or
+		// the profile names one
+		base := baseModel
+		if chatSession.Model != "" && !modelChosen {
+			base = chatSession.Model
+		}
+		chatModel.SetModel(base)
+		if profileModel {
+			chatModel.SetModel(cfg.Model)
+			chatModel.SetBaseModel(base)
+		}
+	}
+	if cfg.Provider == "ollama" {
+		chatModel.SetModelLister(listChatModels)
+	}
+	chatModel.SetSession(store, chatSession)
+	chatModel.SetMemory(recall)
+	chatModel.SetProfiles(chatProfiles(), cfg.Profile)
+	return chatModel, nil
+}
 
-		model, err := p.Run()
-		if err != nil {
-			fmt.Println("Error running program:", err)
-			return
+// openChatSession returns the session to resume for --resume or
+// --continue, or a new one that is saved once the first message is sent.
+func openChatSession() (*session.Store, *session.Session, error) {
+	store, err := openSessionStore()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case resumeID != "":
+		chatSession, err := store.Load(resumeID)
+		return store, chatSession, err
+	case continueLast:
+		// prepareChat reported the sessions skipped
+		chatSession, err := store.Latest()
+		var skipped *session.SkippedError
+		if errors.As(err, &skipped) {
+			err = nil
 		}
+		return store, chatSession, err
+	default:
+		return store, session.New(cfg.Model), nil
+	}
+}
 
-		if chatModel, ok := model.(*chat.Model); ok {
-			if chatModel.Quitting() {
-				cleanup()
-			}
+func newProvider() (ai.Provider, error) {
+	switch cfg.Provider {
+	case "quantum":
+		client := ai.NewClient(serverURLOr("http://localhost:8000"))
+		client.MaxHistoryChars = cfg.HistoryBudget
+		client.Transport = transport()
+		return ai.Timed(client), nil
+	case "ollama":
+		client := ai.NewOllamaClient(cfg.OllamaURL)
+		client.MaxHistoryChars = cfg.HistoryBudget
+		client.Transport = transport()
+		return ai.Timed(client), nil
+	case "openai":
+		client := ai.NewOpenAIClient(serverURLOr("http://localhost:8080/v1"), os.Getenv("OPENAI_API_KEY"))
+		client.MaxHistoryChars = cfg.HistoryBudget
+		client.Transport = transport()
+		return ai.Timed(client), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q, expected quantum, ollama or openai", cfg.Provider)
+	}
+}
+
+// listChatModels offers the installed Ollama models to the chat's model
+// switcher.
+func listChatModels() ([]chat.ModelOption, error) {
+	models, err := ollamaChecker.ListModels()
+	if err != nil {
+		return nil, err
+	}
+	options := make([]chat.ModelOption, len(models))
+	for i, model := range models {
+		options[i] = chat.ModelOption{
+			Name:    model.Name,
+			Details: strings.TrimSpace(model.Details.ParameterSize + " " + model.Details.QuantizationLevel),
 		}
-	},
+	}
+	return options, nil
+}
+
+// transport applies the configured timeouts and retries to the defaults.
+func transport() ai.Transport {
+	transport := ai.DefaultTransport()
+	transport.ConnectTimeout = cfg.ConnectTimeout
+	transport.IdleTimeout = cfg.IdleTimeout
+	transport.MaxRetries = cfg.Retries
+	return transport
+}
+
+func serverURLOr(defaultURL string) string {
+	if cfg.ServerURL == "" {
+		return defaultURL
+	}
+	return cfg.ServerURL
 }
 
 func init() {
-	rootCmd.AddCommand(chatCmd)
+	chatCmd.Flags().StringVar(&resumeID, "resume", "", "resume the saved session with this ID (or unique ID prefix)")
+	chatCmd.Flags().BoolVarP(&continueLast, "continue", "c", false, "continue the most recent session")
+	chatCmd.MarkFlagsMutuallyExclusive("resume", "continue")
+	addRAGFlags(chatCmd)
+	addProfileFlag(chatCmd)
+	chatCmd.Flags().Bool("memory", config.Default().Memory, "recall relevant exchanges of earlier sessions")
+	chatCmd.Flags().Int("history-budget", config.Default().HistoryBudget,
+		"maximum characters of conversation history sent with each message, oldest turns are dropped first (0 for no limit)")
+	toolRegistry.Register(tool.Tool{
+		Name:        "AI chat",
+		Description: "chat with AI",
+		Command:     chatCmd,
+		NewModel:    newChatModel,
+		Check: func() error {
+			_, err := activeProfile()
+			return err
+		},
+	})
 }
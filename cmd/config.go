@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/andreivisan/quantum_cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and edit the qcli configuration",
+	Long: `Inspect and edit the qcli configuration file.
+
+Settings are resolved in this order, later sources winning:
+  1. built-in defaults
+  2. the config file (see 'qcli config path')
+  3. QCLI_* environment variables, e.g. QCLI_MODEL
+  4. command line flags, e.g. --model`,
+	// Reading or editing the configuration does not need Ollama running
+	PersistentPreRun: loadConfigOnly,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := cfg.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Store a setting in the config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		// Edit the file alone so environment and flag overrides are not persisted
+		stored, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+		if err := stored.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		return stored.Save(path)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every setting with its effective value",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, key := range config.Keys() {
+			value, _ := cfg.Get(key)
+			fmt.Printf("%-15s %-30s (%s)\n", key, value, config.EnvVar(key))
+		}
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the location of the config file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+// loadConfigOnly is the PersistentPreRun of commands that work offline
+// and so skip the Ollama checks of rootCmd.
+func loadConfigOnly(cmd *cobra.Command, args []string) {
+	if err := loadConfig(cmd); err != nil {
+		fmt.Printf("Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configPathCmd)
+	rootCmd.AddCommand(configCmd)
+}
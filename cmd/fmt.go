@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andreivisan/quantum_cli/pkg/format"
+	"github.com/andreivisan/quantum_cli/pkg/tool"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fmtFrom   string
+	fmtTo     string
+	fmtMinify bool
+	fmtIndent int
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [file]",
+	Short: "Pretty-print, minify and convert JSON, YAML, TOML and XML",
+	Long: `Read a JSON, YAML, TOML or XML document from a file or stdin and print it
+pretty-printed, or minified with --minify, or converted to another format
+with --to. The input format is guessed from the file extension or the
+content unless --from names it. Invalid input is reported with the line
+and column of the error.
+
+Without a file or piped input, the formatter screen opens: paste a
+document, or type @path to load a file, and see it formatted as you type.
+Tab cycles the output format and Ctrl+Y copies the result.
+
+Conversions keep the order of keys, except to and from TOML, whose keys
+are sorted. Numbers keep their precision between JSON and YAML. XML
+attributes become keys starting with "-" and text next to elements
+becomes "#text".
+
+Usage:
+  qcli fmt
+  qcli fmt config.json
+  curl -s https://api.github.com/repos/golang/go | qcli fmt --to yaml
+  qcli fmt --minify feed.xml
+  qcli fmt pyproject.toml --to json --indent 4`,
+	Args:             cobra.MaximumNArgs(1),
+	PersistentPreRun: loadConfigOnly,
+	SilenceUsage:     true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := format.Options{Indent: fmtIndent, Minify: fmtMinify}
+		if fmtFrom != "" {
+			from, err := format.ParseFormat(fmtFrom)
+			if err != nil {
+				return err
+			}
+			options.From = from
+		}
+		if fmtTo != "" {
+			to, err := format.ParseFormat(fmtTo)
+			if err != nil {
+				return err
+			}
+			options.To = to
+		}
+
+		var data []byte
+		var err error
+		switch {
+		case len(args) == 1:
+			data, err = os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %v", args[0], err)
+			}
+			if options.From == "" {
+				options.From, _ = format.FromPath(args[0])
+			}
+		case !isTerminal(os.Stdin):
+			data, err = io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read stdin: %v", err)
+			}
+		default:
+			return runTool(newFormatModel)
+		}
+
+		if options.From == "" {
+			options.From, err = format.Detect(data)
+			if err != nil {
+				return err
+			}
+		}
+		output, err := format.Convert(data, options)
+		if err != nil {
+			return err
+		}
+		if isTerminal(os.Stdout) {
+			to := options.To
+			if to == "" {
+				to = options.From
+			}
+			fmt.Print(format.Highlight(string(output), to, format.Style(cfg.Theme)))
+			return nil
+		}
+		_, err = os.Stdout.Write(output)
+		return err
+	},
+}
+
+func newFormatModel() (tea.Model, error) {
+	return format.New(format.Style(cfg.Theme), format.DefaultIndent), nil
+}
+
+func init() {
+	fmtCmd.Flags().StringVar(&fmtFrom, "from", "", "input format: json, yaml, toml or xml (default detected)")
+	fmtCmd.Flags().StringVar(&fmtTo, "to", "", "output format: json, yaml, toml or xml (default the input format)")
+	fmtCmd.Flags().BoolVar(&fmtMinify, "minify", false, "remove insignificant whitespace from json and xml")
+	fmtCmd.Flags().IntVar(&fmtIndent, "indent", format.DefaultIndent, "spaces per indentation level")
+	toolRegistry.Register(tool.Tool{
+		Name:        "Formatter",
+		Description: "pretty-print and convert JSON, YAML, TOML and XML",
+		Command:     fmtCmd,
+		NewModel:    newFormatModel,
+	})
+}
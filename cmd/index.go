@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/attach"
+	"github.com/andreivisan/quantum_cli/pkg/rag"
+	"github.com/spf13/cobra"
+)
+
+var (
+	indexName string
+	ragIndex  string
+	ragTopK   int
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index <dir>",
+	Short: "Index a directory for answering questions about it",
+	Long: `Split the source files of a directory into chunks, embed them with an
+Ollama embedding model and save them as a local vector index.
+
+Files excluded by .gitignore, binary files and files over 256 KB are
+skipped. Run the command again to refresh an index after the code changed.
+
+Pass the index to chat or ask with --rag to send the most relevant chunks
+along with every question; the answer cites them as file:line sources.
+
+Usage:
+  qcli index .
+  qcli index ~/src/quantum_cli --name qcli
+  qcli index . --embed-model mxbai-embed-large
+  qcli ask --rag qcli "where are sessions saved?"
+  qcli index list
+  qcli index rm qcli`,
+	Args:         cobra.ExactArgs(1),
+	Annotations:  map[string]string{skipModelCheck: "true"},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		if indexName == "" {
+			indexName = filepath.Base(root)
+			if rag.ValidateName(indexName) != nil {
+				return fmt.Errorf("cannot name the index after %s, pass --name", root)
+			}
+		}
+		if err := rag.ValidateName(indexName); err != nil {
+			return err
+		}
+		if err := ensureModel(cfg.EmbedModel); err != nil {
+			return err
+		}
+
+		loader := attach.NewLoader(root)
+		loader.MaxTotalSize = 0
+		files, err := loader.Load(root)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		start := time.Now()
+		chunks, err := rag.Build(ctx, files, rag.DefaultChunkOptions(), embedder(cfg.EmbedModel), func(done int, total int) {
+			fmt.Fprintf(os.Stderr, "\rEmbedding chunk %d/%d", done, total)
+		})
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		store, err := openIndexStore()
+		if err != nil {
+			return err
+		}
+		index := &rag.Index{
+			Name:      indexName,
+			Root:      root,
+			Model:     cfg.EmbedModel,
+			CreatedAt: time.Now(),
+			Chunks:    chunks,
+		}
+		if err := store.Save(index); err != nil {
+			return err
+		}
+		fmt.Printf("Indexed %d files in %d chunks as %q in %s\n",
+			len(files), len(chunks), indexName, time.Since(start).Round(time.Second))
+		return nil
+	},
+}
+
+var indexListCmd = &cobra.Command{
+	Use:              "list",
+	Aliases:          []string{"ls"},
+	Short:            "List saved indexes",
+	Args:             cobra.NoArgs,
+	PersistentPreRun: loadConfigOnly,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openIndexStore()
+		if err != nil {
+			return err
+		}
+		names, err := store.List()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Println("No indexes yet, create one with 'qcli index <dir>'.")
+			return nil
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, "NAME\tCHUNKS\tMODEL\tCREATED\tDIRECTORY")
+		for _, name := range names {
+			info, err := store.Info(name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t%s\n",
+				info.Name,
+				info.Chunks,
+				info.Model,
+				info.CreatedAt.Local().Format(time.DateTime),
+				info.Root)
+		}
+		return writer.Flush()
+	},
+}
+
+var indexRmCmd = &cobra.Command{
+	Use:              "rm <name>...",
+	Aliases:          []string{"delete"},
+	Short:            "Remove one or more indexes",
+	Args:             cobra.MinimumNArgs(1),
+	PersistentPreRun: loadConfigOnly,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openIndexStore()
+		if err != nil {
+			return err
+		}
+		for _, name := range args {
+			if err := store.Delete(name); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %s\n", name)
+		}
+		return nil
+	},
+}
+
+func openIndexStore() (*rag.Store, error) {
+	dir, err := rag.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return rag.NewStore(dir), nil
+}
+
+// ensureModel offers to pull the Ollama model name when it is missing.
+func ensureModel(name string) error {
+	installed, err := ollamaChecker.HasModel(name)
+	if err != nil {
+		return fmt.Errorf("failed to check installed models: %w", err)
+	}
+	if installed {
+		return nil
+	}
+	if !confirm(fmt.Sprintf("Model %s is not installed. Would you like to pull it?", name)) {
+		return fmt.Errorf("model %s is required, pull it with 'qcli models pull %s'", name, name)
+	}
+	return pullModel(name)
+}
+
+func embedder(model string) rag.Embedder {
+	return func(ctx context.Context, text string) ([]float32, error) {
+		return ollamaChecker.Embed(ctx, model, text)
+	}
+}
+
+// indexRetriever returns the retriever of the --rag index, or nil when
+// none was given.
+func indexRetriever() (rag.Retriever, error) {
+	if ragIndex == "" {
+		return nil, nil
+	}
+	store, err := openIndexStore()
+	if err != nil {
+		return nil, err
+	}
+	index, err := store.Load(ragIndex)
+	if err != nil {
+		return nil, err
+	}
+	return index.Retriever(embedder(index.Model), ragTopK), nil
+}
+
+// withRetrievers wraps provider to send what the --rag index and extra
+// find along with each question.
+func withRetrievers(provider ai.Provider, extra ...rag.Retriever) (ai.Provider, error) {
+	retriever, err := indexRetriever()
+	if err != nil {
+		return nil, err
+	}
+	retrievers := extra
+	if retriever != nil {
+		retrievers = append([]rag.Retriever{retriever}, extra...)
+	}
+	if len(retrievers) == 0 {
+		return provider, nil
+	}
+	return rag.Augment(provider, retrievers...), nil
+}
+
+// addRAGFlags registers --rag and --rag-top-k on cmd.
+func addRAGFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&ragIndex, "rag", "", "answer with the most relevant chunks of this index, see 'qcli index'")
+	cmd.Flags().IntVar(&ragTopK, "rag-top-k", 5, "number of chunks retrieved from the --rag index per question")
+}
+
+func init() {
+	indexCmd.Flags().StringVar(&indexName, "name", "", "name of the index (default the directory name)")
+	indexCmd.Flags().String("embed-model", "", "Ollama embedding model (default the embed_model setting)")
+	indexCmd.AddCommand(indexListCmd, indexRmCmd)
+	rootCmd.AddCommand(indexCmd)
+}
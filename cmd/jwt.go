@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/format"
+	"github.com/andreivisan/quantum_cli/pkg/jwt"
+	"github.com/andreivisan/quantum_cli/pkg/tool"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jwtSecret  string
+	jwtKeyFile string
+	jwtAlg     string
+	jwtKeyID   string
+	jwtExpires time.Duration
+)
+
+var jwtCmd = &cobra.Command{
+	Use:   "jwt",
+	Short: "Decode, verify and sign JSON Web Tokens",
+	Long: `Inspect and create JSON Web Tokens. Without a subcommand the JWT screen
+opens: paste a token to see its header and claims, and enter a secret or
+the path of a key file to check its signature. Ctrl+S switches it to
+signing the claims typed in with a secret or private key.
+
+Usage:
+  qcli jwt
+  qcli jwt decode eyJhbGciOi...
+  qcli jwt verify --key jwks.json < token.txt
+  echo '{"sub":"42"}' | qcli jwt sign --secret s3cret --expires 1h`,
+	PersistentPreRun: loadConfigOnly,
+	SilenceUsage:     true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTool(newJWTModel)
+	},
+}
+
+var jwtDecodeCmd = &cobra.Command{
+	Use:   "decode [token]",
+	Short: "Show the header and claims of a token",
+	Long: `Print the header and claims of a token, read from the argument or stdin,
+with exp, iat and nbf as local times and warnings such as an expired
+token. The signature is not checked, see 'qcli jwt verify'.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token, err := readToken(args)
+		if err != nil {
+			return err
+		}
+		decoded, err := jwt.Decode(token)
+		if err != nil {
+			return err
+		}
+		printDecoded(decoded)
+		return nil
+	},
+}
+
+var jwtVerifyCmd = &cobra.Command{
+	Use:   "verify [token]",
+	Short: "Check the signature and times of a token",
+	Long: `Check the signature of a token, read from the argument or stdin, with an
+HMAC secret or the keys of a PEM or JWKS file. HS256/384/512, RS*, PS*,
+ES* and EdDSA are supported. With a JWKS, the key matching the kid of the
+token is used.
+
+The command fails if the signature is invalid, the token has expired or
+is not valid yet.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token, err := readToken(args)
+		if err != nil {
+			return err
+		}
+		keys, err := jwtKeys()
+		if err != nil {
+			return err
+		}
+		decoded, err := jwt.Decode(token)
+		if err != nil {
+			return err
+		}
+		key, err := jwt.Verify(token, keys)
+		if err != nil {
+			return err
+		}
+		verified := "Signature verified with " + decoded.Algorithm()
+		if key.ID != "" {
+			verified += fmt.Sprintf(" (key %s)", key.ID)
+		}
+		fmt.Println(verified)
+		return decoded.Valid(time.Now())
+	},
+}
+
+var jwtSignCmd = &cobra.Command{
+	Use:   "sign [claims.json]",
+	Short: "Sign a new token from a claims JSON",
+	Long: `Sign the claims of a JSON object, read from a file or stdin, and print the
+token. HMAC algorithms take --secret, the others a private key in a PEM
+file given with --key.
+
+Usage:
+  qcli jwt sign claims.json --secret s3cret
+  qcli jwt sign claims.json --alg RS256 --key private.pem --kid 2024-05
+  echo '{"sub":"42","role":"admin"}' | qcli jwt sign --secret s3cret --expires 15m`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var claims []byte
+		var err error
+		if len(args) == 1 {
+			claims, err = os.ReadFile(args[0])
+		} else {
+			claims, err = io.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the claims: %v", err)
+		}
+		keys, err := jwtKeys()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 1 {
+			return fmt.Errorf("%s holds %d keys, sign with a file holding one", jwtKeyFile, len(keys))
+		}
+		token, err := jwt.Sign(claims, jwt.SignOptions{
+			Algorithm: jwtAlg,
+			Key:       keys[0],
+			KeyID:     jwtKeyID,
+			ExpiresIn: jwtExpires,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(token)
+		return nil
+	},
+}
+
+func newJWTModel() (tea.Model, error) {
+	return jwt.New(format.Style(cfg.Theme)), nil
+}
+
+// readToken returns the token of args, or of stdin without one.
+func readToken(args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	if isTerminal(os.Stdin) {
+		return "", errors.New("pass a token or pipe it to stdin")
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %v", err)
+	}
+	return string(data), nil
+}
+
+// jwtKeys returns the key of --secret or the keys of --key.
+func jwtKeys() ([]jwt.Key, error) {
+	switch {
+	case jwtSecret != "" && jwtKeyFile != "":
+		return nil, errors.New("pass either --secret or --key, not both")
+	case jwtSecret != "":
+		return []jwt.Key{jwt.SecretKey(jwtSecret)}, nil
+	case jwtKeyFile != "":
+		return jwt.LoadKeys(jwtKeyFile)
+	default:
+		return nil, errors.New("pass a secret with --secret or a PEM or JWKS file with --key")
+	}
+}
+
+func printDecoded(decoded *jwt.Decoded) {
+	style := format.Style(cfg.Theme)
+	highlight := func(text string) string {
+		if !isTerminal(os.Stdout) {
+			return text
+		}
+		return format.Highlight(text, format.JSON, style)
+	}
+	fmt.Printf("Header:\n%s\n\nClaims:\n%s\n", highlight(decoded.HeaderJSON), highlight(decoded.ClaimsJSON))
+	now := time.Now()
+	if times := jwt.Describe(decoded, now); times != "" {
+		fmt.Printf("\nTimes:\n%s\n", times)
+	}
+	if warnings := decoded.Warnings(now); len(warnings) > 0 {
+		fmt.Printf("\nWarning: %s\n", strings.Join(warnings, "\nWarning: "))
+	}
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{jwtVerifyCmd, jwtSignCmd} {
+		cmd.Flags().StringVar(&jwtSecret, "secret", "", "HMAC secret")
+		cmd.Flags().StringVar(&jwtKeyFile, "key", "", "PEM or JWKS file with the key")
+	}
+	jwtSignCmd.Flags().StringVar(&jwtAlg, "alg", "HS256", "signing algorithm: "+strings.Join(jwt.Algorithms, ", "))
+	jwtSignCmd.Flags().StringVar(&jwtKeyID, "kid", "", "key ID to put in the header")
+	jwtSignCmd.Flags().DurationVar(&jwtExpires, "expires", 0, "set iat to now and exp this long after, e.g. 1h")
+	jwtCmd.AddCommand(jwtDecodeCmd, jwtVerifyCmd, jwtSignCmd)
+	toolRegistry.Register(tool.Tool{
+		Name:        "JWT",
+		Description: "decode, verify and sign JSON Web Tokens",
+		Command:     jwtCmd,
+		NewModel:    newJWTModel,
+	})
+}
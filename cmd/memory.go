@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/memory"
+	"github.com/andreivisan/quantum_cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var memoryLimit int
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Search and manage what qcli remembers of earlier chats",
+	Long: `qcli remembers the questions and answers of saved chat sessions by
+embedding them with the embed_model. When memory is on, the chat recalls
+relevant exchanges of earlier sessions with every question. Turn it on
+with 'qcli config set memory true', 'qcli chat --memory' or /memory on.
+
+The memory is brought up to date with the saved sessions when a chat with
+memory starts and before every search; deleted sessions are forgotten.
+
+Usage:
+  qcli memory search "what did we decide about the retry policy?"
+  qcli memory sync
+  qcli memory clear`,
+	Annotations: map[string]string{skipModelCheck: "true"},
+}
+
+var memorySearchCmd = &cobra.Command{
+	Use:          "search <query>",
+	Short:        "Find exchanges of earlier sessions related to a query",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		remembered, err := loadMemory(ctx)
+		if err != nil {
+			return err
+		}
+		if len(remembered.Exchanges) == 0 {
+			fmt.Println("Nothing remembered yet, chat first.")
+			return nil
+		}
+		embedding, err := ollamaChecker.Embed(ctx, remembered.Model, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		results := remembered.Search(embedding, memoryLimit, 0, "")
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, "SCORE\tSESSION\tDATE\tTITLE\tQUESTION")
+		for _, result := range results {
+			fmt.Fprintf(writer, "%.2f\t%s\t%s\t%s\t%s\n",
+				result.Score,
+				result.SessionID,
+				result.CreatedAt.Local().Format(time.DateOnly),
+				result.Title,
+				firstLine(result.Question, 60))
+		}
+		return writer.Flush()
+	},
+}
+
+var memorySyncCmd = &cobra.Command{
+	Use:          "sync",
+	Short:        "Embed the exchanges of new sessions",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		remembered, err := loadMemory(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Remembering %d exchanges.\n", len(remembered.Exchanges))
+		return nil
+	},
+}
+
+var memoryClearCmd = &cobra.Command{
+	Use:              "clear",
+	Short:            "Forget everything, the saved sessions stay",
+	Args:             cobra.NoArgs,
+	PersistentPreRun: loadConfigOnly,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := memory.DefaultPath()
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to clear memory: %w", err)
+		}
+		fmt.Println("Memory cleared.")
+		return nil
+	},
+}
+
+// loadMemory reads the memory and brings it up to date with the saved
+// sessions, showing progress on stderr.
+func loadMemory(ctx context.Context) (*memory.Memory, error) {
+	if err := ensureModel(cfg.EmbedModel); err != nil {
+		return nil, err
+	}
+	path, err := memory.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	remembered, err := memory.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := syncMemory(ctx, remembered, path, printProgress); err != nil {
+		return nil, err
+	}
+	return remembered, nil
+}
+
+// syncMemory embeds the exchanges of new sessions into remembered and
+// saves it to path, keeping what was embedded before an error.
+func syncMemory(ctx context.Context, remembered *memory.Memory, path string, progress func(done int, total int)) error {
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	sessions, err := store.List()
+	var skipped *session.SkippedError
+	switch {
+	case errors.As(err, &skipped):
+		// Unreadable sessions are left out, only reported outside the chat
+		if progress != nil {
+			warnSkipped(err)
+		}
+	case err != nil:
+		return err
+	}
+	added, syncErr := remembered.Sync(ctx, sessions, cfg.EmbedModel, embedder(cfg.EmbedModel), progress)
+	if added > 0 || syncErr == nil {
+		if err := remembered.Save(path); err != nil {
+			return err
+		}
+	}
+	return syncErr
+}
+
+// memoryRetriever returns the chat's memory, which /memory switches on
+// and off. It is synced on first use, without prompting or printing as
+// the chat may already own the screen; with the memory setting on,
+// prepareChat syncs it before a chat started from the command line.
+func memoryRetriever(exclude string) (*memory.Retriever, error) {
+	path, err := memory.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	remembered, err := memory.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	retriever := memory.NewRetriever(remembered, embedder(cfg.EmbedModel))
+	retriever.Exclude = exclude
+	retriever.Sync = func(ctx context.Context) error {
+		// ensureModel cannot ask to pull the model while the chat runs
+		installed, err := ollamaChecker.HasModel(cfg.EmbedModel)
+		if err != nil {
+			return fmt.Errorf("failed to check installed models: %w", err)
+		}
+		if !installed {
+			return fmt.Errorf("model %s is required, pull it with 'qcli models pull %s'", cfg.EmbedModel, cfg.EmbedModel)
+		}
+		return syncMemory(ctx, remembered, path, nil)
+	}
+	retriever.SetEnabled(cfg.Memory)
+	return retriever, nil
+}
+
+// printProgress shows how many exchanges were embedded on one stderr line.
+func printProgress(done int, total int) {
+	fmt.Fprintf(os.Stderr, "\rRemembering exchange %d/%d", done, total)
+	if done == total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// firstLine returns the first line of text, cut to max runes.
+func firstLine(text string, max int) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if runes := []rune(line); len(runes) > max {
+		return string(runes[:max-1]) + "…"
+	}
+	return line
+}
+
+func init() {
+	memorySearchCmd.Flags().IntVarP(&memoryLimit, "limit", "n", 5, "number of exchanges to show")
+	memoryCmd.AddCommand(memorySearchCmd, memorySyncCmd, memoryClearCmd)
+	rootCmd.AddCommand(memoryCmd)
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/ollama"
+	"github.com/andreivisan/quantum_cli/pkg/pull"
+	"github.com/andreivisan/quantum_cli/pkg/units"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Manage the Ollama models available to qcli",
+	Long: `List, download, inspect and remove Ollama models.
+
+Usage:
+  qcli models list
+  qcli models pull qwq
+  qcli models show qwq
+  qcli models rm qwq`,
+	Annotations: map[string]string{skipModelCheck: "true"},
+}
+
+var modelsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List installed models",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		models, err := ollamaChecker.ListModels()
+		if err != nil {
+			return err
+		}
+		sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, "NAME\tPARAMETERS\tSIZE\tMODIFIED")
+		for _, model := range models {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+				model.Name,
+				model.Details.ParameterSize,
+				units.FormatSize(model.Size),
+				model.ModifiedAt.Local().Format(time.DateTime))
+		}
+		return writer.Flush()
+	},
+}
+
+var modelsPullCmd = &cobra.Command{
+	Use:   "pull <model>",
+	Short: "Download a model with a progress bar",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return pullModel(args[0])
+	},
+}
+
+var modelsRmCmd = &cobra.Command{
+	Use:     "rm <model>...",
+	Aliases: []string{"delete"},
+	Short:   "Remove one or more models",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range args {
+			if err := ollamaChecker.DeleteModel(name); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %s\n", name)
+		}
+		return nil
+	},
+}
+
+var modelsShowCmd = &cobra.Command{
+	Use:   "show <model>",
+	Short: "Show the details, parameters and template of a model",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := ollamaChecker.ShowModel(args[0])
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintf(writer, "Model\t%s\n", args[0])
+		fmt.Fprintf(writer, "Family\t%s\n", info.Details.Family)
+		fmt.Fprintf(writer, "Parameters\t%s\n", info.Details.ParameterSize)
+		fmt.Fprintf(writer, "Quantization\t%s\n", info.Details.QuantizationLevel)
+		fmt.Fprintf(writer, "Format\t%s\n", info.Details.Format)
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if info.Parameters != "" {
+			fmt.Printf("\nParameters:\n%s\n", indent(info.Parameters))
+		}
+		if info.Template != "" {
+			fmt.Printf("\nTemplate:\n%s\n", indent(info.Template))
+		}
+		return nil
+	},
+}
+
+// pullModel downloads name while showing a Bubble Tea progress bar.
+func pullModel(name string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	progressChan := make(chan ollama.PullProgress)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(progressChan)
+		errChan <- ollamaChecker.PullModel(ctx, name, progressChan)
+	}()
+
+	finalModel, err := tea.NewProgram(pull.New(name, progressChan)).Run()
+	if err != nil {
+		return fmt.Errorf("error running program: %w", err)
+	}
+	if pullUI, ok := finalModel.(*pull.Model); ok && pullUI.Quitting() {
+		cancel()
+		<-errChan
+		return fmt.Errorf("pull of %s cancelled", name)
+	}
+	return <-errChan
+}
+
+func indent(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	return "  " + strings.Join(lines, "\n  ")
+}
+
+func init() {
+	modelsCmd.AddCommand(modelsListCmd, modelsPullCmd, modelsRmCmd, modelsShowCmd)
+	rootCmd.AddCommand(modelsCmd)
+}
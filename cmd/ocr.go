@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/ocr"
+	"github.com/andreivisan/quantum_cli/pkg/tool"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var ocrSave bool
+
+var ocrCmd = &cobra.Command{
+	Use:   "ocr [file...]",
+	Short: "Extract the text of images with a vision model",
+	Long: `Send PNG or JPEG images to an Ollama vision model and print the text it
+reads from them. Without files, the OCR screen opens: type image paths,
+watch the text stream in, then copy it with Ctrl+Y or save it next to the
+images with Ctrl+S.
+
+With several files each text is headed by its path. With --save the text
+of every image is written next to it as a .txt file instead.
+
+Images must be at most 20 MB. The vision model is the vision_model setting,
+llama3.2-vision by default, and is pulled on first use.
+
+Usage:
+  qcli ocr
+  qcli ocr screenshot.png
+  qcli ocr scans/*.jpg --save
+  qcli ocr receipt.jpg --vision-model llava`,
+	Annotations:  map[string]string{skipModelCheck: "true"},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var images []ocr.Image
+		for _, path := range args {
+			image, err := ocr.LoadImage(path)
+			if err != nil {
+				return err
+			}
+			images = append(images, image)
+		}
+		if err := ensureModel(cfg.VisionModel); err != nil {
+			return err
+		}
+		if len(images) == 0 {
+			return runTool(newOCRModel)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		provider := ocrProvider()
+		for i, image := range images {
+			if ocrSave {
+				if err := saveOCR(ctx, provider, image); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(images) > 1 {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("==> %s <==\n", image.Path)
+			}
+			if err := extractText(ctx, provider, image, func(text string) { fmt.Print(text) }); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+func newOCRModel() (tea.Model, error) {
+	return ocr.New(ocrProvider(), cfg.VisionModel), nil
+}
+
+// ocrProvider talks to Ollama whatever the chat provider is, since images
+// travel in Ollama's images field.
+func ocrProvider() ai.Provider {
+	client := ai.NewOllamaClient(cfg.OllamaURL)
+	client.SystemPrompt = ""
+	client.Transport = transport()
+	return client
+}
+
+// extractText passes the text of image to write as it is read.
+func extractText(ctx context.Context, provider ai.Provider, image ocr.Image, write func(text string)) error {
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(textChan)
+		errChan <- ocr.Extract(ctx, provider, cfg.VisionModel, image, textChan)
+	}()
+	for text := range textChan {
+		write(text)
+	}
+	if err := <-errChan; err != nil {
+		return fmt.Errorf("failed to read %s: %v", image.Path, err)
+	}
+	return nil
+}
+
+func saveOCR(ctx context.Context, provider ai.Provider, image ocr.Image) error {
+	var text strings.Builder
+	if err := extractText(ctx, provider, image, func(chunk string) { text.WriteString(chunk) }); err != nil {
+		return err
+	}
+	path := ocr.TextPath(image.Path)
+	if err := os.WriteFile(path, []byte(text.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to save %s: %v", path, err)
+	}
+	fmt.Printf("Saved %s\n", path)
+	return nil
+}
+
+func init() {
+	ocrCmd.Flags().BoolVar(&ocrSave, "save", false, "write the text of each image to a .txt file next to it")
+	ocrCmd.Flags().String("vision-model", "", "Ollama vision model (default the vision_model setting)")
+	toolRegistry.Register(tool.Tool{
+		Name:        "AI OCR",
+		Description: "extract text from images",
+		Command:     ocrCmd,
+		NewModel:    newOCRModel,
+		Check: func() error {
+			installed, err := ollamaChecker.HasModel(cfg.VisionModel)
+			if err != nil {
+				return fmt.Errorf("failed to check installed models: %w", err)
+			}
+			if !installed {
+				return fmt.Errorf("pull %s first with 'qcli models pull %s'", cfg.VisionModel, cfg.VisionModel)
+			}
+			return nil
+		},
+	})
+}
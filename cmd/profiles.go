@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/chat"
+	"github.com/andreivisan/quantum_cli/pkg/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+var profileSystemFile string
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Create, edit and list system prompt profiles",
+	Long: `Profiles are named personas stored in the config file: a system prompt
+and, optionally, the model, temperature, top_p and num_ctx to answer with.
+Pick one with --profile on chat and ask, /profile in the chat, or make it
+the default with 'qcli config set profile <name>'. The profile's model
+wins over the one in the config file, but --model and QCLI_MODEL win over
+the profile's.
+
+Usage:
+  qcli profiles create reviewer --system "You review Go code. Be blunt." --temperature 0.2
+  qcli profiles create writer --system-file prompts/writer.md --model llama3.2
+  qcli profiles edit reviewer --num-ctx 16384
+  qcli profiles edit reviewer
+  qcli profiles list
+  qcli profiles show reviewer
+  qcli profiles rm writer
+  qcli chat --profile reviewer`,
+	PersistentPreRun: loadConfigOnly,
+}
+
+var profilesListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List profiles",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(cfg.Profiles) == 0 {
+			fmt.Println("No profiles yet, create one with 'qcli profiles create <name>'.")
+			return nil
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, "NAME\tMODEL\tTEMPERATURE\tTOP_P\tNUM_CTX\tSYSTEM PROMPT")
+		for _, name := range cfg.ProfileNames() {
+			profile := cfg.Profiles[name]
+			if name == cfg.Profile {
+				name += " (default)"
+			}
+			values := []string{name}
+			for _, key := range []string{"model", "temperature", "top_p", "num_ctx"} {
+				value, _ := profile.Get(key)
+				if value == "" {
+					value = "-"
+				}
+				values = append(values, value)
+			}
+			values = append(values, firstLine(profile.SystemPrompt, 50))
+			fmt.Fprintln(writer, strings.Join(values, "\t"))
+		}
+		return writer.Flush()
+	},
+}
+
+var profilesShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := cfg.LookupProfile(args[0])
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(profile)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	},
+}
+
+var profilesCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile from flags",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := config.ValidateProfileName(name); err != nil {
+			return err
+		}
+		err := editStoredProfiles(func(stored *config.Config) error {
+			if _, ok := stored.Profiles[name]; ok {
+				return fmt.Errorf("profile %s already exists, change it with 'qcli profiles edit %s'", name, name)
+			}
+			profile := config.Profile{}
+			if err := applyProfileFlags(cmd, &profile); err != nil {
+				return err
+			}
+			if stored.Profiles == nil {
+				stored.Profiles = map[string]config.Profile{}
+			}
+			stored.Profiles[name] = profile
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created profile %s\n", name)
+		return nil
+	},
+}
+
+var profilesEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Change a profile from flags, or in $EDITOR without flags",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		err := editStoredProfiles(func(stored *config.Config) error {
+			profile, err := stored.LookupProfile(name)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().NFlag() > 0 {
+				err = applyProfileFlags(cmd, &profile)
+			} else {
+				profile, err = editInEditor(profile)
+			}
+			if err != nil {
+				return err
+			}
+			stored.Profiles[name] = profile
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved profile %s\n", name)
+		return nil
+	},
+}
+
+var profilesRmCmd = &cobra.Command{
+	Use:     "rm <name>...",
+	Aliases: []string{"delete"},
+	Short:   "Remove one or more profiles",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := editStoredProfiles(func(stored *config.Config) error {
+			for _, name := range args {
+				if _, err := stored.LookupProfile(name); err != nil {
+					return err
+				}
+				delete(stored.Profiles, name)
+				if stored.Profile == name {
+					stored.Profile = ""
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range args {
+			fmt.Printf("Deleted %s\n", name)
+		}
+		return nil
+	},
+}
+
+// editStoredProfiles applies edit to the config file alone, so environment
+// and flag overrides are not persisted, and saves it.
+func editStoredProfiles(edit func(stored *config.Config) error) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	stored, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := edit(stored); err != nil {
+		return err
+	}
+	return stored.Save(path)
+}
+
+// profileFlags maps the flags of create and edit to profile keys.
+var profileFlags = map[string]string{
+	"system":      "system_prompt",
+	"model":       "model",
+	"temperature": "temperature",
+	"top-p":       "top_p",
+	"num-ctx":     "num_ctx",
+}
+
+func applyProfileFlags(cmd *cobra.Command, profile *config.Profile) error {
+	var err error
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if key, ok := profileFlags[flag.Name]; ok && err == nil {
+			err = profile.Set(key, flag.Value.String())
+		}
+	})
+	if err != nil || profileSystemFile == "" {
+		return err
+	}
+	prompt, err := os.ReadFile(profileSystemFile)
+	if err != nil {
+		return fmt.Errorf("failed to read system prompt: %w", err)
+	}
+	profile.SystemPrompt = strings.TrimSpace(string(prompt))
+	return nil
+}
+
+// editInEditor lets the user change profile as YAML in $VISUAL or $EDITOR.
+func editInEditor(profile config.Profile) (config.Profile, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	data, err := yaml.Marshal(profile)
+	if err != nil {
+		return profile, err
+	}
+	file, err := os.CreateTemp("", "qcli-profile-*.yaml")
+	if err != nil {
+		return profile, err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return profile, err
+	}
+	if err := file.Close(); err != nil {
+		return profile, err
+	}
+
+	fields := strings.Fields(editor)
+	editCmd := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return profile, fmt.Errorf("error running %s: %w", editor, err)
+	}
+
+	data, err = os.ReadFile(file.Name())
+	if err != nil {
+		return profile, err
+	}
+	var edited config.Profile
+	if err := yaml.Unmarshal(data, &edited); err != nil {
+		return profile, fmt.Errorf("invalid profile: %w", err)
+	}
+	if err := edited.Validate(); err != nil {
+		return profile, err
+	}
+	return edited, nil
+}
+
+// activeProfile returns the profile picked by --profile or the profile
+// setting, the zero Profile when there is none.
+func activeProfile() (config.Profile, error) {
+	if cfg.Profile == "" {
+		return config.Profile{}, nil
+	}
+	return cfg.LookupProfile(cfg.Profile)
+}
+
+// requestOptions returns the sampling options of profile, nil when it
+// sets none.
+func requestOptions(profile config.Profile) *ai.Options {
+	if profile.Temperature == nil && profile.TopP == nil && profile.NumCtx == 0 {
+		return nil
+	}
+	return &ai.Options{Temperature: profile.Temperature, TopP: profile.TopP, NumCtx: profile.NumCtx}
+}
+
+// chatProfiles offers the configured profiles to /profile.
+func chatProfiles() []chat.Profile {
+	var profiles []chat.Profile
+	for _, name := range cfg.ProfileNames() {
+		profile := cfg.Profiles[name]
+		profiles = append(profiles, chat.Profile{
+			Name:    name,
+			System:  profile.SystemPrompt,
+			Model:   profile.Model,
+			Options: requestOptions(profile),
+		})
+	}
+	return profiles
+}
+
+func addProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().String("profile", "", "answer with this profile's system prompt and options, see 'qcli profiles'")
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{profilesCreateCmd, profilesEditCmd} {
+		cmd.Flags().String("system", "", "system prompt")
+		cmd.Flags().StringVar(&profileSystemFile, "system-file", "", "read the system prompt from a file")
+		cmd.Flags().String("model", "", "model to answer with")
+		cmd.Flags().String("temperature", "", "sampling temperature from 0 to 2")
+		cmd.Flags().String("top-p", "", "nucleus sampling probability from 0 to 1")
+		cmd.Flags().String("num-ctx", "", "context window in tokens, Ollama only")
+		cmd.MarkFlagsMutuallyExclusive("system", "system-file")
+	}
+	profilesCmd.AddCommand(profilesListCmd, profilesShowCmd, profilesCreateCmd, profilesEditCmd, profilesRmCmd)
+	rootCmd.AddCommand(profilesCmd)
+}
@@ -9,13 +9,49 @@ import (
 	"os"
 	"strings"
 
-	"github.com/andreivisan/quantum_cli/pkg/menu"
+	"github.com/andreivisan/quantum_cli/pkg/config"
 	"github.com/andreivisan/quantum_cli/pkg/ollama"
+	"github.com/andreivisan/quantum_cli/pkg/router"
+	"github.com/andreivisan/quantum_cli/pkg/tool"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-var ollamaChecker *ollama.Checker
+var (
+	ollamaChecker *ollama.Checker
+	cfg           *config.Config
+	stdinReader   = bufio.NewReader(os.Stdin)
+	// toolRegistry holds the tools of the main menu. Their commands are
+	// added to rootCmd by Execute.
+	toolRegistry = &tool.Registry{}
+	// baseModel is cfg.Model before the active profile replaced it, which
+	// the chat switches back to with /profile none.
+	baseModel string
+	// modelChosen is set when the config file, QCLI_MODEL or --model
+	// picked baseModel rather than the default.
+	modelChosen bool
+	// profileModel is set when the active profile replaced cfg.Model.
+	profileModel bool
+)
+
+// skipModelCheck is the annotation of commands that must not check for
+// the configured model on startup, such as the model management itself.
+const skipModelCheck = "qcli/skip-model-check"
+
+// configFlags maps command line flags to the config keys they override.
+var configFlags = map[string]string{
+	"provider":       "provider",
+	"server":         "server",
+	"ollama-url":     "ollama_url",
+	"model":          "model",
+	"theme":          "theme",
+	"history-budget": "history_budget",
+	"embed-model":    "embed_model",
+	"vision-model":   "vision_model",
+	"memory":         "memory",
+	"profile":        "profile",
+}
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -28,7 +64,11 @@ This CLI tool allows you to:
 • Have natural conversations with an AI
 • Enjoy a clean, terminal-based UI for your AI interactions`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		ollamaChecker = ollama.NewChecker("http://localhost:11434")
+		if err := loadConfig(cmd); err != nil {
+			fmt.Printf("Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+		ollamaChecker = ollama.NewChecker(cfg.OllamaURL)
 
 		// Check if Ollama is installed
 		if !ollamaChecker.CheckInstallation() {
@@ -43,12 +83,7 @@ This CLI tool allows you to:
 
 		// Check if server is running and offer to start it
 		if !ollamaChecker.IsServerRunning() {
-			fmt.Println("Ollama server is not running. Would you like to start it? (yes/no)")
-			reader := bufio.NewReader(os.Stdin)
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-
-			if response == "yes" || response == "y" {
+			if confirm("Ollama server is not running. Would you like to start it?") {
 				fmt.Println("Starting Ollama server...")
 				if err := ollamaChecker.StartServer(); err != nil {
 					fmt.Printf("Failed to start Ollama server: %v\n", err)
@@ -61,11 +96,30 @@ This CLI tool allows you to:
 				os.Exit(1)
 			}
 		}
+
+		// Check if the configured model is installed and offer to pull it
+		if cfg.Provider == "ollama" && !skipsModelCheck(cmd) {
+			installed, err := ollamaChecker.HasModel(cfg.Model)
+			if err != nil {
+				fmt.Printf("Failed to check installed models: %v\n", err)
+				os.Exit(1)
+			}
+			if !installed {
+				if !confirm(fmt.Sprintf("Model %s is not installed. Would you like to pull it?", cfg.Model)) {
+					fmt.Printf("You can pull it later by running 'qcli models pull %s'.\n", cfg.Model)
+					os.Exit(1)
+				}
+				if err := pullModel(cfg.Model); err != nil {
+					fmt.Printf("Failed to pull %s: %v\n", cfg.Model, err)
+					os.Exit(1)
+				}
+			}
+		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			p := tea.NewProgram(
-				menu.New(),
+				router.New(toolRegistry),
 				tea.WithAltScreen(),
 			)
 
@@ -75,24 +129,20 @@ This CLI tool allows you to:
 				os.Exit(1)
 			}
 
-			if menuModel, ok := finalModel.(menu.Model); ok {
-				if menuModel.Quitting() {
-					cleanup()
-					os.Exit(0)
-				}
-				if menuModel.Choice() == "AI chat" {
-					chatCmd.Run(cmd, args)
-				}
+			if finalRouter, ok := finalModel.(*router.Model); ok && finalRouter.Quitting() {
+				cleanup()
+				os.Exit(0)
 			}
 		}
 	},
 }
 
 var stopCmd = &cobra.Command{
-	Use:   "stop",
-	Short: "Stop the Ollama server",
+	Use:         "stop",
+	Short:       "Stop the Ollama server",
+	Annotations: map[string]string{skipModelCheck: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
-		ollamaChecker = ollama.NewChecker("http://localhost:11434")
+		ollamaChecker = ollama.NewChecker(cfg.OllamaURL)
 		if !ollamaChecker.IsServerRunning() {
 			fmt.Println("Ollama server is not running.")
 			return
@@ -109,6 +159,88 @@ var stopCmd = &cobra.Command{
 	},
 }
 
+// loadConfig resolves the configuration for cmd: defaults, then the
+// config file, then QCLI_* environment variables, then explicit flags.
+func loadConfig(cmd *cobra.Command) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	loaded, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := loaded.ApplyEnv(os.LookupEnv); err != nil {
+		return err
+	}
+
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		key, ok := configFlags[flag.Name]
+		if ok && err == nil {
+			err = loaded.Set(key, flag.Value.String())
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// A config file naming the default model cannot be told apart from
+	// one that names none, which only matters when resuming a session.
+	_, envModel := os.LookupEnv(config.EnvVar("model"))
+	explicitModel := envModel || cmd.Flags().Changed("model")
+	modelChosen = explicitModel || loaded.Model != config.Default().Model
+	baseModel = loaded.Model
+
+	// A profile's model wins over the config file's, while QCLI_MODEL and
+	// --model win over the profile as they are chosen for this run alone.
+	// An unknown profile is reported by the commands that use it, so it
+	// can still be fixed with qcli config and qcli profiles.
+	profile, ok := loaded.Profiles[loaded.Profile]
+	profileModel = ok && profile.Model != "" && !explicitModel
+	if profileModel {
+		loaded.Model = profile.Model
+	}
+	cfg = loaded
+	return nil
+}
+
+// confirm asks a yes/no question on the terminal. Without a terminal,
+// for example when input is piped to 'qcli ask', the answer is no.
+func confirm(question string) bool {
+	fmt.Printf("%s (yes/no)\n", question)
+	if !isTerminal(os.Stdin) {
+		return false
+	}
+	response, _ := stdinReader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "yes" || response == "y"
+}
+
+func skipsModelCheck(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd.Annotations[skipModelCheck] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// runTool runs the screen returned by newModel until the user leaves it.
+func runTool(newModel func() (tea.Model, error)) error {
+	model, err := newModel()
+	if err != nil {
+		return err
+	}
+	finalModel, err := tea.NewProgram(model, tea.WithAltScreen()).Run()
+	if err != nil {
+		return fmt.Errorf("error running program: %w", err)
+	}
+	if quitter, ok := finalModel.(interface{ Quitting() bool }); ok && quitter.Quitting() {
+		cleanup()
+	}
+	return nil
+}
+
 func cleanup() {
 	if ollamaChecker != nil && ollamaChecker.ServerStartedByUs {
 		fmt.Println("Stopping Ollama server...")
@@ -121,10 +253,18 @@ func cleanup() {
 }
 
 func init() {
+	defaults := config.Default()
+	rootCmd.PersistentFlags().String("provider", defaults.Provider, "AI backend: quantum, ollama or openai")
+	rootCmd.PersistentFlags().String("server", defaults.ServerURL, "server URL for the quantum and openai providers")
+	rootCmd.PersistentFlags().String("ollama-url", defaults.OllamaURL, "Ollama server URL")
+	rootCmd.PersistentFlags().String("model", defaults.Model, "model used by the ollama and openai providers")
+	rootCmd.PersistentFlags().String("theme", defaults.Theme, "Markdown theme: auto, dark, light, dracula, tokyo-night, pink, ascii or notty")
+
 	rootCmd.AddCommand(stopCmd)
 }
 
 func Execute() {
+	toolRegistry.AddCommands(rootCmd)
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
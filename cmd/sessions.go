@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:     "sessions",
+	Aliases: []string{"session"},
+	Short:   "Manage saved chat sessions",
+	Long: `List, read, rename and delete the chat sessions qcli saves automatically.
+
+Sessions are stored as JSON files under $XDG_DATA_HOME/qcli/sessions
+(~/.local/share/qcli/sessions by default). Any command taking an ID also
+accepts a unique prefix of it.
+
+Resume a session with 'qcli chat --resume <id>' or the latest one with
+'qcli chat --continue'.`,
+	PersistentPreRun: loadConfigOnly,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List saved sessions, most recent first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore()
+		if err != nil {
+			return err
+		}
+		sessions, err := store.List()
+		if err := warnSkipped(err); err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, "ID\tTITLE\tMODEL\tMESSAGES\tUPDATED")
+		for _, saved := range sessions {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%s\n",
+				saved.ID,
+				saved.Title,
+				saved.Model,
+				len(saved.Messages),
+				saved.UpdatedAt.Local().Format(time.DateTime))
+		}
+		return writer.Flush()
+	},
+}
+
+var sessionsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Print the transcript of a session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore()
+		if err != nil {
+			return err
+		}
+		saved, err := store.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s\n%s  %s\n", saved.Title, saved.ID, saved.Model)
+		for _, message := range saved.Messages {
+			fmt.Printf("\n%s (%s):\n%s\n", session.RoleLabel(message.Role), message.CreatedAt.Local().Format(time.DateTime), strings.TrimSpace(message.Content))
+			if message.Interrupted {
+				fmt.Println("[interrupted]")
+			}
+		}
+		return nil
+	},
+}
+
+var sessionsRmCmd = &cobra.Command{
+	Use:     "rm <id>...",
+	Aliases: []string{"delete"},
+	Short:   "Delete one or more sessions",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore()
+		if err != nil {
+			return err
+		}
+		for _, id := range args {
+			if err := store.Delete(id); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %s\n", id)
+		}
+		return nil
+	},
+}
+
+var sessionsRenameCmd = &cobra.Command{
+	Use:   "rename <id> <title>",
+	Short: "Change the title of a session",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore()
+		if err != nil {
+			return err
+		}
+		return store.Rename(args[0], strings.Join(args[1:], " "))
+	},
+}
+
+var (
+	exportFormat   string
+	exportOutput   string
+	exportThinking bool
+)
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export a session to Markdown, JSON or HTML",
+	Long: `Export a session with its title, model and timestamps.
+
+Usage:
+  qcli sessions export 20241205-101500-3fa2c91e --format md > retry-policy.md
+  qcli sessions export 20241205 --format html --output retry-policy.html --thinking`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := session.ParseFormat(exportFormat)
+		if err != nil {
+			return err
+		}
+		store, err := openSessionStore()
+		if err != nil {
+			return err
+		}
+		saved, err := store.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		output := os.Stdout
+		if exportOutput != "" && exportOutput != "-" {
+			output, err = os.Create(exportOutput)
+			if err != nil {
+				return err
+			}
+			defer output.Close()
+		}
+		return session.Export(output, saved, session.ExportOptions{
+			Format:          format,
+			IncludeThinking: exportThinking,
+		})
+	},
+}
+
+// warnSkipped prints the sessions List skipped to stderr and returns nil
+// for them, keeping other errors.
+func warnSkipped(err error) error {
+	var skipped *session.SkippedError
+	if errors.As(err, &skipped) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+func openSessionStore() (*session.Store, error) {
+	dir, err := session.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return session.NewStore(dir), nil
+}
+
+func init() {
+	sessionsExportCmd.Flags().StringVarP(&exportFormat, "format", "f", "md", "export format: md, json or html")
+	sessionsExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write instead of stdout")
+	sessionsExportCmd.Flags().BoolVar(&exportThinking, "thinking", false, "include the model's reasoning before each answer")
+
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsShowCmd, sessionsRmCmd, sessionsRenameCmd, sessionsExportCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
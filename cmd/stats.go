@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var statsSince time.Duration
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show token usage and latency of saved chat sessions",
+	Long: `Summarise the token usage and speed of the AI replies in saved sessions,
+per model and in total.
+
+Replies saved before qcli recorded statistics are not counted.
+
+Usage:
+  qcli stats
+  qcli stats --since 168h`,
+	PersistentPreRun: loadConfigOnly,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore()
+		if err != nil {
+			return err
+		}
+		sessions, err := store.List()
+		if err := warnSkipped(err); err != nil {
+			return err
+		}
+		if statsSince > 0 {
+			cutoff := time.Now().Add(-statsSince)
+			recent := sessions[:0]
+			for _, saved := range sessions {
+				if saved.UpdatedAt.After(cutoff) {
+					recent = append(recent, saved)
+				}
+			}
+			sessions = recent
+		}
+
+		byModel, total := session.Aggregate(sessions)
+		if total.Replies == 0 {
+			fmt.Println("No replies with statistics yet.")
+			return nil
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, "MODEL\tSESSIONS\tREPLIES\tPROMPT TOKENS\tCOMPLETION TOKENS\tAVG FIRST TOKEN\tAVG DURATION\tTOK/S")
+		for _, stats := range byModel {
+			printStats(writer, stats)
+		}
+		if len(byModel) > 1 {
+			total.Model = "total"
+			printStats(writer, total)
+		}
+		return writer.Flush()
+	},
+}
+
+func printStats(writer *tabwriter.Writer, stats session.Stats) {
+	model := stats.Model
+	if model == "" {
+		model = "(server default)"
+	}
+	fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%d\t%.1fs\t%.1fs\t%.1f\n",
+		model,
+		stats.Sessions,
+		stats.Replies,
+		stats.PromptTokens,
+		stats.CompletionTokens,
+		stats.AverageTimeToFirstToken().Seconds(),
+		stats.AverageDuration().Seconds(),
+		stats.TokensPerSecond())
+}
+
+func init() {
+	statsCmd.Flags().DurationVar(&statsSince, "since", 0, "only count sessions updated within this duration, e.g. 24h")
+	rootCmd.AddCommand(statsCmd)
+}
@@ -1,9 +1,10 @@
 package ai
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,29 +12,48 @@ import (
 
 type Client struct {
 	ServerURL string
+	// MaxHistoryChars caps the size of the conversation sent with each
+	// request. Zero means the full history is always sent.
+	MaxHistoryChars int
+	Transport       Transport
 }
 
-type ChatRequest struct {
-	Message string `json:"message"`
+// quantumRequest is the body of a quantum_server /chat/stream request.
+// Message repeats the latest user turn for servers that predate Messages,
+// which also ignore System and Options.
+type quantumRequest struct {
+	Message  string    `json:"message"`
+	Messages []Message `json:"messages"`
+	System   string    `json:"system,omitempty"`
+	Options  *Options  `json:"options,omitempty"`
 }
 
 func NewClient(serverURL string) *Client {
 	return &Client{
 		ServerURL: serverURL,
+		Transport: DefaultTransport(),
 	}
 }
 
-func (cli *Client) Chat(message string, outputChan chan<- string) error {
-	request := ChatRequest{
-		Message: message,
+// Chat sends the conversation history to the quantum_server and streams
+// the reply to its last message into outputChan as typed events. The
+// server picks the model, so request.Model is ignored. Cancelling ctx
+// aborts the request and Chat returns the context's error.
+func (cli *Client) Chat(ctx context.Context, request ChatRequest, outputChan chan<- Event) error {
+	messages := TruncateHistory(request.Messages, cli.MaxHistoryChars)
+	body := quantumRequest{
+		Message:  lastUserMessage(messages),
+		Messages: messages,
+		System:   request.System,
+		Options:  request.Options,
 	}
 
-	jsonRequest, err := json.Marshal(request)
+	jsonRequest, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("error marshalling request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", cli.ServerURL+"/chat/stream", bytes.NewBuffer(jsonRequest))
+	req, err := http.NewRequestWithContext(ctx, "POST", cli.ServerURL+"/chat/stream", bytes.NewBuffer(jsonRequest))
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
@@ -43,64 +63,54 @@ func (cli *Client) Chat(message string, outputChan chan<- string) error {
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := cli.Transport.do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr
+		}
 		return fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
+	if err := emit(ctx, outputChan, Event{Type: EventStart}); err != nil {
+		return err
+	}
 
-	reader := bufio.NewReader(resp.Body)
-	buffer := make([]byte, 1)
-	word := ""
-	isThinking := false
-
-	outputChan <- "Thinking...\n"
-
+	reader := newSSEReader(resp.Body)
 	for {
-		n, err := reader.Read(buffer)
+		sseEvent, err := reader.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			return fmt.Errorf("error reading stream: %w", err)
 		}
 
-		if n > 0 {
-			char := string(buffer[0])
-			word += char
-
-			// Check for section changes (words ending with ":")
-			if char == ":" {
-				if word == "THINKING:" {
-					isThinking = true
-					word = ""
-					continue
-				} else if len(word) > 0 && word[0] >= 'A' && word[0] <= 'Z' {
-					// Any other section header
-					isThinking = false
-					outputChan <- word
-					word = ""
-					continue
-				}
-			}
-
-			// Send word when we hit a space or newline
-			if char == " " || char == "\n" {
-				if word != "" {
-					if !isThinking {
-						outputChan <- word
-					}
-					word = ""
-				}
+		switch sseEvent.Event {
+		case "thinking":
+			err = emit(ctx, outputChan, Event{Type: EventThinking, Text: sseEvent.Data})
+		case "answer", "message":
+			err = emit(ctx, outputChan, Event{Type: EventAnswer, Text: sseEvent.Data})
+		case "error":
+			return emit(ctx, outputChan, Event{Type: EventError, Text: sseEvent.Data})
+		case "done":
+			// Newer servers report the token usage in the done event
+			var usage *Usage
+			if json.Unmarshal([]byte(sseEvent.Data), &usage) != nil {
+				usage = nil
 			}
+			return emit(ctx, outputChan, Event{Type: EventDone, Usage: usage})
+		}
+		if err != nil {
+			return err
 		}
 	}
 
-	// Send any remaining word
-	if word != "" && !isThinking {
-		outputChan <- word
-	}
-
-	return nil
+	return emit(ctx, outputChan, Event{Type: EventDone})
 }
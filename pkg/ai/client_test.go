@@ -1,8 +1,12 @@
 package ai
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -13,20 +17,38 @@ func TestClient_Chat(t *testing.T) {
 		name       string
 		message    string
 		serverResp string
+		wantAnswer string
+		wantUsage  *Usage
 		wantErr    bool
 	}{
 		{
 			name:       "successful chat",
 			message:    "Hello",
-			serverResp: "THINKING:\nResponse text here",
+			serverResp: "event: thinking\ndata: Let me think\n\nevent: answer\ndata: Response text here\n\nevent: done\ndata:\n\n",
+			wantAnswer: "Response text here",
 			wantErr:    false,
 		},
 		{
 			name:       "empty message",
 			message:    "",
-			serverResp: "THINKING:\nEmpty message received",
+			serverResp: "event: answer\ndata: Empty message received\n\n",
+			wantAnswer: "Empty message received",
 			wantErr:    false,
 		},
+		{
+			name:       "section headers are not mangled",
+			message:    "Explain",
+			serverResp: "event: answer\ndata: Note: see Example: below\n\nevent: answer\ndata:  — naïve café\n\n",
+			wantAnswer: "Note: see Example: below — naïve café",
+			wantErr:    false,
+		},
+		{
+			name:       "usage in done event",
+			message:    "Hello",
+			serverResp: "event: answer\ndata: Hi\n\nevent: done\ndata: {\"prompt_tokens\":5,\"completion_tokens\":2}\n\n",
+			wantAnswer: "Hi",
+			wantUsage:  &Usage{PromptTokens: 5, CompletionTokens: 2},
+		},
 	}
 
 	for _, tt := range tests {
@@ -40,6 +62,13 @@ func TestClient_Chat(t *testing.T) {
 				if r.Header.Get("Content-Type") != "application/json" {
 					t.Errorf("Expected Content-Type: application/json, got %s", r.Header.Get("Content-Type"))
 				}
+				var request quantumRequest
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("Expected JSON body, got error %v", err)
+				}
+				if request.Message != tt.message || len(request.Messages) != 1 {
+					t.Errorf("Unexpected request body %+v", request)
+				}
 
 				// Write response in chunks to simulate streaming
 				flusher, ok := w.(http.Flusher)
@@ -64,21 +93,25 @@ func TestClient_Chat(t *testing.T) {
 
 			// Create client with test server URL
 			client := NewClient(ts.URL)
-			outputChan := make(chan string)
+			outputChan := make(chan Event)
 
 			// Run chat in goroutine
 			done := make(chan bool)
 			var chatErr error
 			go func() {
-				chatErr = client.Chat(tt.message, outputChan)
+				chatErr = client.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: tt.message}}}, outputChan)
 				close(outputChan)
 				done <- true
 			}()
 
 			// Collect response
-			var response []string
-			for msg := range outputChan {
-				response = append(response, msg)
+			var response []Event
+			var answer strings.Builder
+			for event := range outputChan {
+				response = append(response, event)
+				if event.Type == EventAnswer {
+					answer.WriteString(event.Text)
+				}
 			}
 
 			<-done // Wait for chat to complete
@@ -88,10 +121,117 @@ func TestClient_Chat(t *testing.T) {
 				t.Errorf("Client.Chat() error = %v, wantErr %v", chatErr, tt.wantErr)
 			}
 
-			// Verify we got some response
+			// Verify we got the answer and a final done event
 			if len(response) == 0 && !tt.wantErr {
-				t.Error("Expected non-empty response")
+				t.Fatal("Expected non-empty response")
+			}
+			if got := answer.String(); got != tt.wantAnswer {
+				t.Errorf("Client.Chat() answer = %q, want %q", got, tt.wantAnswer)
+			}
+			if first := response[0]; first.Type != EventStart {
+				t.Errorf("Client.Chat() first event = %v, want %v", first.Type, EventStart)
+			}
+			last := response[len(response)-1]
+			if last.Type != EventDone {
+				t.Errorf("Client.Chat() last event = %v, want %v", last.Type, EventDone)
+			}
+			if !reflect.DeepEqual(last.Usage, tt.wantUsage) {
+				t.Errorf("Client.Chat() usage = %+v, want %+v", last.Usage, tt.wantUsage)
 			}
 		})
 	}
 }
+
+func TestClient_Chat_Cancel(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: answer\ndata: partial\n\n"))
+		w.(http.Flusher).Flush()
+		// Keep the stream open until the client goes away
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	outputChan := make(chan Event)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- NewClient(ts.URL).Chat(ctx, ChatRequest{Messages: []Message{{Role: RoleUser, Content: "Hello"}}}, outputChan)
+	}()
+
+	if event := <-outputChan; event.Type != EventStart {
+		t.Fatalf("Client.Chat() first event = %+v, want start", event)
+	}
+	if event := <-outputChan; event.Text != "partial" {
+		t.Fatalf("Client.Chat() second event = %+v, want partial answer", event)
+	}
+	cancel()
+
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Client.Chat() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Client.Chat() did not return after cancel")
+	}
+}
+
+func TestClient_Chat_History(t *testing.T) {
+	var request quantumRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Expected JSON body, got error %v", err)
+		}
+		_, _ = w.Write([]byte("event: done\ndata:\n\n"))
+	}))
+	defer ts.Close()
+
+	history := []Message{
+		{Role: RoleUser, Content: "write a sort in Python"},
+		{Role: RoleAssistant, Content: "sorted(xs)"},
+		{Role: RoleUser, Content: "now rewrite that in Go"},
+	}
+
+	client := NewClient(ts.URL)
+	client.MaxHistoryChars = len(history[2].Content)
+	outputChan := make(chan Event, 2)
+	if err := client.Chat(context.Background(), ChatRequest{Messages: history}, outputChan); err != nil {
+		t.Fatalf("Client.Chat() unexpected error: %v", err)
+	}
+
+	if request.Message != "now rewrite that in Go" {
+		t.Errorf("request.Message = %q, want latest user turn", request.Message)
+	}
+	if !reflect.DeepEqual(request.Messages, history[2:]) {
+		t.Errorf("request.Messages = %+v, want only the latest turn", request.Messages)
+	}
+}
+
+func TestClient_Chat_HTTPError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"detail":"model is still loading"}`, http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	client.Transport = fastTransport
+	outputChan := make(chan Event, 1)
+	err := client.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "Hello"}}}, outputChan)
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Client.Chat() error = %v, want *HTTPError", err)
+	}
+	if httpErr.StatusCode != http.StatusServiceUnavailable || httpErr.Body != `{"detail":"model is still loading"}` {
+		t.Errorf("Client.Chat() error = %+v, want the status and body", httpErr)
+	}
+	if len(outputChan) != 0 {
+		t.Errorf("Client.Chat() emitted %v, want no answer from an error page", <-outputChan)
+	}
+}
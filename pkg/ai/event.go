@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies the kind of a streamed chat event. A reply is an
+// EventStart, any number of EventThinking and EventAnswer deltas, and
+// exactly one EventDone or EventError, unless the stream fails before it
+// starts.
+type EventType int
+
+const (
+	// EventThinking carries a delta of the model's chain-of-thought.
+	EventThinking EventType = iota
+	// EventAnswer carries a delta of the final answer.
+	EventAnswer
+	// EventDone marks the end of a reply and carries its Usage when the
+	// backend reports one.
+	EventDone
+	// EventError carries an error reported by the server.
+	EventError
+	// EventStart marks that the backend accepted the request and the reply
+	// is about to stream.
+	EventStart
+)
+
+func (eventType EventType) String() string {
+	switch eventType {
+	case EventThinking:
+		return "thinking"
+	case EventAnswer:
+		return "answer"
+	case EventDone:
+		return "done"
+	case EventError:
+		return "error"
+	case EventStart:
+		return "start"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a single typed delta of a streamed chat reply.
+type Event struct {
+	Type EventType
+	Text string
+	// Usage is set on EventDone.
+	Usage *Usage
+	// Sources is set on EventDone when the question was sent along with
+	// retrieved context, see rag.Augment. They are shown after the answer
+	// but are not part of it.
+	Sources []string
+}
+
+// Usage is the token count of a reply as reported by the backend and,
+// once measured by Timed, how long it took.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	// TimeToFirstToken is the wait from sending the request until the
+	// first thinking or answer text arrived.
+	TimeToFirstToken time.Duration `json:"time_to_first_token,omitempty"`
+	Duration         time.Duration `json:"duration,omitempty"`
+}
+
+// TokensPerSecond is the generation speed once the first token arrived,
+// or zero when the backend did not report a completion token count.
+func (usage Usage) TokensPerSecond() float64 {
+	generation := usage.Duration - usage.TimeToFirstToken
+	if usage.CompletionTokens == 0 || generation <= 0 {
+		return 0
+	}
+	return float64(usage.CompletionTokens) / generation.Seconds()
+}
+
+// emit sends event on outputChan unless ctx is cancelled first, so a
+// stream never blocks on a consumer that stopped listening.
+func emit(ctx context.Context, outputChan chan<- Event, event Event) error {
+	select {
+	case outputChan <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
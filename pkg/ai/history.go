@@ -0,0 +1,65 @@
+package ai
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single turn of a conversation.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Images are base64-encoded PNG or JPEG images for multimodal models,
+	// sent through Ollama's images field.
+	Images []string `json:"images,omitempty"`
+}
+
+// TruncateHistory drops the oldest turns of history until the combined
+// content fits in maxChars. System messages and the latest turn are
+// always kept, so the result may still exceed a very small budget.
+// A maxChars of zero or less disables truncation.
+func TruncateHistory(history []Message, maxChars int) []Message {
+	if maxChars <= 0 || len(history) == 0 {
+		return history
+	}
+
+	total := 0
+	for _, message := range history {
+		total += len(message.Content)
+	}
+
+	dropped := make([]bool, len(history))
+	droppedAny := false
+	for i := 0; i < len(history)-1; i++ {
+		if history[i].Role == RoleSystem {
+			continue
+		}
+		// Once a question is gone its answer goes too, so the kept
+		// history never starts in the middle of an exchange
+		orphaned := droppedAny && history[i].Role == RoleAssistant
+		if total <= maxChars && !orphaned {
+			break
+		}
+		dropped[i] = true
+		droppedAny = true
+		total -= len(history[i].Content)
+	}
+
+	truncated := make([]Message, 0, len(history))
+	for i, message := range history {
+		if !dropped[i] {
+			truncated = append(truncated, message)
+		}
+	}
+	return truncated
+}
+
+func lastUserMessage(history []Message) string {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == RoleUser {
+			return history[i].Content
+		}
+	}
+	return ""
+}
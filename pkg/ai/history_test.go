@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTruncateHistory(t *testing.T) {
+	system := Message{Role: RoleSystem, Content: "be brief"}
+	question := Message{Role: RoleUser, Content: "write a sort"}
+	answer := Message{Role: RoleAssistant, Content: "def sort(xs): ..."}
+	followUp := Message{Role: RoleUser, Content: "now rewrite that in Go"}
+
+	tests := []struct {
+		name     string
+		history  []Message
+		maxChars int
+		want     []Message
+	}{
+		{
+			name:     "no budget keeps everything",
+			history:  []Message{question, answer, followUp},
+			maxChars: 0,
+			want:     []Message{question, answer, followUp},
+		},
+		{
+			name:     "history within budget",
+			history:  []Message{question, answer, followUp},
+			maxChars: 1000,
+			want:     []Message{question, answer, followUp},
+		},
+		{
+			name:     "oldest exchange dropped whole",
+			history:  []Message{question, answer, followUp},
+			maxChars: len(answer.Content) + len(followUp.Content),
+			want:     []Message{followUp},
+		},
+		{
+			name:     "system prompt is kept",
+			history:  []Message{system, question, answer, followUp},
+			maxChars: len(system.Content) + len(followUp.Content),
+			want:     []Message{system, followUp},
+		},
+		{
+			name:     "latest turn kept over budget",
+			history:  []Message{question, answer, followUp},
+			maxChars: 1,
+			want:     []Message{followUp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateHistory(tt.history, tt.maxChars)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TruncateHistory() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
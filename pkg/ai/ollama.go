@@ -0,0 +1,171 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultSystemPrompt asks a chain-of-thought model such as QwQ to keep
+// its reasoning apart from a concise final answer.
+const DefaultSystemPrompt = `You are a helpful assistant for software developers.
+Think through the problem step by step before answering.
+Start your reply with a line containing only "THINKING:" followed by your reasoning.
+Then write a line containing only "ANSWER:" followed by a concise, well formatted Markdown answer.
+Do not repeat your reasoning in the answer.`
+
+// OllamaClient talks to Ollama's native /api/chat endpoint, so no
+// separate quantum_server is needed.
+type OllamaClient struct {
+	OllamaURL string
+	// SystemPrompt is sent as the first message unless the request has its
+	// own or the conversation already carries a system message.
+	SystemPrompt    string
+	MaxHistoryChars int
+	Transport       Transport
+}
+
+type ollamaChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Options  *Options  `json:"options,omitempty"`
+}
+
+type ollamaChatChunk struct {
+	Message struct {
+		Content  string `json:"content"`
+		Thinking string `json:"thinking"`
+	} `json:"message"`
+	Done  bool   `json:"done"`
+	Error string `json:"error"`
+	// The final chunk reports how many tokens were read and generated
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+func NewOllamaClient(ollamaURL string) *OllamaClient {
+	return &OllamaClient{
+		OllamaURL:    ollamaURL,
+		SystemPrompt: DefaultSystemPrompt,
+		Transport:    DefaultTransport(),
+	}
+}
+
+// Chat streams the reply from Ollama's NDJSON /api/chat stream into
+// outputChan. Reasoning is reported as EventThinking, either from the
+// message's thinking field or from THINKING:/ANSWER: and <think> markers
+// in the content.
+func (cli *OllamaClient) Chat(ctx context.Context, request ChatRequest, outputChan chan<- Event) error {
+	messages := withSystemPrompt(TruncateHistory(request.Messages, cli.MaxHistoryChars), request, cli.SystemPrompt)
+	jsonRequest, err := json.Marshal(ollamaChatRequest{
+		Model:    request.Model,
+		Messages: messages,
+		Stream:   true,
+		Options:  request.Options,
+	})
+	if err != nil {
+		return fmt.Errorf("error marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cli.OllamaURL+"/api/chat", bytes.NewBuffer(jsonRequest))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := cli.Transport.do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Ollama explains failures such as a missing model in the body
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			var chunk ollamaChatChunk
+			if json.Unmarshal([]byte(httpErr.Body), &chunk) == nil && chunk.Error != "" {
+				return emit(ctx, outputChan, Event{Type: EventError, Text: chunk.Error})
+			}
+			return httpErr
+		}
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := emit(ctx, outputChan, Event{Type: EventStart}); err != nil {
+		return err
+	}
+
+	splitter := newSectionSplitter()
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("error decoding stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return emit(ctx, outputChan, Event{Type: EventError, Text: chunk.Error})
+		}
+
+		events := splitter.Write(chunk.Message.Content)
+		if chunk.Message.Thinking != "" {
+			events = append([]Event{{Type: EventThinking, Text: chunk.Message.Thinking}}, events...)
+		}
+		if chunk.Done {
+			events = append(events, splitter.Flush()...)
+			done := Event{Type: EventDone}
+			if chunk.PromptEvalCount > 0 || chunk.EvalCount > 0 {
+				done.Usage = &Usage{PromptTokens: chunk.PromptEvalCount, CompletionTokens: chunk.EvalCount}
+			}
+			events = append(events, done)
+		}
+		for _, event := range events {
+			if err := emit(ctx, outputChan, event); err != nil {
+				return err
+			}
+		}
+		if chunk.Done {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("error reading stream: %w", err)
+	}
+
+	for _, event := range append(splitter.Flush(), Event{Type: EventDone}) {
+		if err := emit(ctx, outputChan, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withSystemPrompt prepends the request's system prompt, or fallback,
+// unless messages carry a system message already.
+func withSystemPrompt(messages []Message, request ChatRequest, fallback string) []Message {
+	prompt := request.System
+	if prompt == "" {
+		prompt = fallback
+	}
+	if prompt == "" {
+		return messages
+	}
+	for _, message := range messages {
+		if message.Role == RoleSystem {
+			return messages
+		}
+	}
+	return append([]Message{{Role: RoleSystem, Content: prompt}}, messages...)
+}
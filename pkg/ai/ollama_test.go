@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestOllamaClient_Chat(t *testing.T) {
+	tests := []struct {
+		name       string
+		history    []Message
+		system     string
+		options    *Options
+		chunks     []string
+		wantSystem string
+		want       []Event
+	}{
+		{
+			name:       "chain of thought sections",
+			history:    []Message{{Role: RoleUser, Content: "Hello"}},
+			wantSystem: DefaultSystemPrompt,
+			chunks: []string{
+				`{"message":{"role":"assistant","content":"THINK"},"done":false}`,
+				`{"message":{"role":"assistant","content":"ING:\nthe user greets\n"},"done":false}`,
+				`{"message":{"role":"assistant","content":"ANSWER:\nHi there"},"done":false}`,
+				`{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":3}`,
+			},
+			want: []Event{
+				{Type: EventStart},
+				{Type: EventThinking, Text: "the user greets\n"},
+				{Type: EventAnswer, Text: "Hi there"},
+				{Type: EventDone, Usage: &Usage{PromptTokens: 12, CompletionTokens: 3}},
+			},
+		},
+		{
+			name: "custom system prompt and thinking field",
+			history: []Message{
+				{Role: RoleSystem, Content: "be terse"},
+				{Role: RoleUser, Content: "Hello"},
+			},
+			wantSystem: "be terse",
+			chunks: []string{
+				`{"message":{"role":"assistant","thinking":"hmm","content":"Hi"},"done":false}`,
+				`{"message":{"role":"assistant","content":""},"done":true}`,
+			},
+			want: []Event{
+				{Type: EventStart},
+				{Type: EventThinking, Text: "hmm"},
+				{Type: EventAnswer, Text: "Hi"},
+				{Type: EventDone},
+			},
+		},
+		{
+			name:       "request system prompt and options",
+			history:    []Message{{Role: RoleUser, Content: "Hello"}},
+			system:     "You are a pirate.",
+			options:    &Options{Temperature: float64Ptr(0), TopP: float64Ptr(0.9), NumCtx: 8192},
+			wantSystem: "You are a pirate.",
+			chunks:     []string{`{"message":{"role":"assistant","content":"Arr"},"done":true}`},
+			want:       []Event{{Type: EventStart}, {Type: EventAnswer, Text: "Arr"}, {Type: EventDone}},
+		},
+		{
+			name:       "images",
+			history:    []Message{{Role: RoleUser, Content: "Extract the text", Images: []string{"iVBORw0KGgo="}}},
+			wantSystem: DefaultSystemPrompt,
+			chunks:     []string{`{"message":{"role":"assistant","content":"TOTAL 12.50"},"done":true}`},
+			want:       []Event{{Type: EventStart}, {Type: EventAnswer, Text: "TOTAL 12.50"}, {Type: EventDone}},
+		},
+		{
+			name:       "error object",
+			history:    []Message{{Role: RoleUser, Content: "Hello"}},
+			wantSystem: DefaultSystemPrompt,
+			chunks:     []string{`{"error":"model 'qwq' not found"}`},
+			want:       []Event{{Type: EventStart}, {Type: EventError, Text: "model 'qwq' not found"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/chat" {
+					t.Errorf("Expected /api/chat, got %s", r.URL.Path)
+				}
+				var request ollamaChatRequest
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("Expected JSON body, got error %v", err)
+				}
+				if request.Model != "qwq" || !request.Stream {
+					t.Errorf("Unexpected request %+v", request)
+				}
+				if len(request.Messages) == 0 || request.Messages[0].Content != tt.wantSystem {
+					t.Errorf("Expected system prompt %q, got %+v", tt.wantSystem, request.Messages)
+				}
+				if last := request.Messages[len(request.Messages)-1]; !reflect.DeepEqual(last.Images, tt.history[len(tt.history)-1].Images) {
+					t.Errorf("Expected images %v, got %v", tt.history[len(tt.history)-1].Images, last.Images)
+				}
+				if !reflect.DeepEqual(request.Options, tt.options) {
+					t.Errorf("Expected options %+v, got %+v", tt.options, request.Options)
+				}
+
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				for _, chunk := range tt.chunks {
+					_, _ = w.Write([]byte(chunk + "\n"))
+					w.(http.Flusher).Flush()
+				}
+			}))
+			defer ts.Close()
+
+			outputChan := make(chan Event)
+			errChan := make(chan error, 1)
+			go func() {
+				errChan <- NewOllamaClient(ts.URL).Chat(context.Background(), ChatRequest{Model: "qwq", Messages: tt.history, System: tt.system, Options: tt.options}, outputChan)
+				close(outputChan)
+			}()
+
+			var got []Event
+			for event := range outputChan {
+				got = append(got, event)
+			}
+			if err := <-errChan; err != nil {
+				t.Fatalf("OllamaClient.Chat() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OllamaClient.Chat() events = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
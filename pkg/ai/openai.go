@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAIClient talks to any server speaking the OpenAI chat completions
+// streaming API, such as llama.cpp server, vLLM or LM Studio.
+type OpenAIClient struct {
+	// BaseURL is the API root including the version, for example
+	// http://localhost:8080/v1.
+	BaseURL string
+	APIKey  string
+	// SystemPrompt is sent as the first message unless the request has its
+	// own or the conversation already carries a system message.
+	SystemPrompt    string
+	MaxHistoryChars int
+	Transport       Transport
+}
+
+type openAIChatRequest struct {
+	Model         string              `json:"model,omitempty"`
+	Messages      []Message           `json:"messages"`
+	Stream        bool                `json:"stream"`
+	StreamOptions openAIStreamOptions `json:"stream_options"`
+	Temperature   *float64            `json:"temperature,omitempty"`
+	TopP          *float64            `json:"top_p,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIChatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage       `json:"usage"`
+	Error *openAIError `json:"error"`
+}
+
+type openAIError struct {
+	Message string `json:"message"`
+}
+
+func NewOpenAIClient(baseURL string, apiKey string) *OpenAIClient {
+	return &OpenAIClient{
+		BaseURL:      strings.TrimSuffix(baseURL, "/"),
+		APIKey:       apiKey,
+		SystemPrompt: DefaultSystemPrompt,
+		Transport:    DefaultTransport(),
+	}
+}
+
+// Chat streams the reply from the /chat/completions endpoint into
+// outputChan. Reasoning is reported as EventThinking, either from the
+// delta's reasoning_content field or from markers in the content.
+func (cli *OpenAIClient) Chat(ctx context.Context, request ChatRequest, outputChan chan<- Event) error {
+	body := openAIChatRequest{
+		Model:         request.Model,
+		Messages:      withSystemPrompt(TruncateHistory(request.Messages, cli.MaxHistoryChars), request, cli.SystemPrompt),
+		Stream:        true,
+		StreamOptions: openAIStreamOptions{IncludeUsage: true},
+	}
+	// The API has no way to set the context window, so NumCtx is dropped
+	if request.Options != nil {
+		body.Temperature = request.Options.Temperature
+		body.TopP = request.Options.TopP
+	}
+
+	jsonRequest, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cli.BaseURL+"/chat/completions", bytes.NewBuffer(jsonRequest))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if cli.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cli.APIKey)
+	}
+
+	resp, err := cli.Transport.do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			var chunk openAIChatChunk
+			if json.Unmarshal([]byte(httpErr.Body), &chunk) == nil && chunk.Error != nil {
+				return emit(ctx, outputChan, Event{Type: EventError, Text: chunk.Error.Message})
+			}
+			return httpErr
+		}
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := emit(ctx, outputChan, Event{Type: EventStart}); err != nil {
+		return err
+	}
+
+	var usage *Usage
+	splitter := newSectionSplitter()
+	reader := newSSEReader(resp.Body)
+	for {
+		sseEvent, err := reader.Next()
+		if err == io.EOF || sseEvent.Data == "[DONE]" {
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("error reading stream: %w", err)
+		}
+
+		var chunk openAIChatChunk
+		if err := json.Unmarshal([]byte(sseEvent.Data), &chunk); err != nil {
+			return fmt.Errorf("error decoding stream: %w", err)
+		}
+		if chunk.Error != nil {
+			return emit(ctx, outputChan, Event{Type: EventError, Text: chunk.Error.Message})
+		}
+		// With include_usage the last chunk carries the usage and no choices
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+
+		var events []Event
+		for _, choice := range chunk.Choices {
+			if choice.Delta.ReasoningContent != "" {
+				events = append(events, Event{Type: EventThinking, Text: choice.Delta.ReasoningContent})
+			}
+			events = append(events, splitter.Write(choice.Delta.Content)...)
+		}
+		for _, event := range events {
+			if err := emit(ctx, outputChan, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, event := range append(splitter.Flush(), Event{Type: EventDone, Usage: usage}) {
+		if err := emit(ctx, outputChan, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestOpenAIClient_Chat(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    []Event
+		wantErr bool
+	}{
+		{
+			name:   "streamed completion",
+			status: http.StatusOK,
+			body: "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"reasoning_content\":\"hmm\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Hi \"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"there\"},\"finish_reason\":\"stop\"}]}\n\n" +
+				"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":9,\"completion_tokens\":4}}\n\n" +
+				"data: [DONE]\n\n",
+			want: []Event{
+				{Type: EventStart},
+				{Type: EventThinking, Text: "hmm"},
+				{Type: EventAnswer, Text: "Hi "},
+				{Type: EventAnswer, Text: "there"},
+				{Type: EventDone, Usage: &Usage{PromptTokens: 9, CompletionTokens: 4}},
+			},
+		},
+		{
+			name:   "think tags in content",
+			status: http.StatusOK,
+			body: "data: {\"choices\":[{\"delta\":{\"content\":\"<think>plan</think>\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Answer\"}}]}\n\n" +
+				"data: [DONE]\n\n",
+			want: []Event{
+				{Type: EventStart},
+				{Type: EventThinking, Text: "plan"},
+				{Type: EventAnswer, Text: "Answer"},
+				{Type: EventDone},
+			},
+		},
+		{
+			name:   "error response",
+			status: http.StatusNotFound,
+			body:   `{"error":{"message":"model not found","type":"invalid_request_error"}}`,
+			want:   []Event{{Type: EventError, Text: "model not found"}},
+		},
+		{
+			name:    "non-json error response",
+			status:  http.StatusBadGateway,
+			body:    "bad gateway",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/chat/completions" {
+					t.Errorf("Expected /v1/chat/completions, got %s", r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+					t.Errorf("Expected bearer token, got %q", got)
+				}
+				var request openAIChatRequest
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("Expected JSON body, got error %v", err)
+				}
+				if request.Model != "qwen2.5-coder" || !request.Stream || !request.StreamOptions.IncludeUsage || request.Messages[0].Role != RoleSystem {
+					t.Errorf("Unexpected request %+v", request)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			outputChan := make(chan Event)
+			errChan := make(chan error, 1)
+			go func() {
+				request := ChatRequest{Model: "qwen2.5-coder", Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+				client := NewOpenAIClient(ts.URL+"/v1/", "secret")
+				// Retries are covered by the transport tests
+				client.Transport = Transport{}
+				errChan <- client.Chat(context.Background(), request, outputChan)
+				close(outputChan)
+			}()
+
+			var got []Event
+			for event := range outputChan {
+				got = append(got, event)
+			}
+			err := <-errChan
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenAIClient.Chat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OpenAIClient.Chat() events = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIClient_Chat_Options(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Expected JSON body, got error %v", err)
+		}
+		if request["temperature"] != 0.2 || request["top_p"] != 0.9 || request["num_ctx"] != nil {
+			t.Errorf("Expected temperature and top_p only, got %+v", request)
+		}
+		messages, _ := request["messages"].([]any)
+		if len(messages) == 0 || messages[0].(map[string]any)["content"] != "You are a pirate." {
+			t.Errorf("Expected the request's system prompt, got %+v", messages)
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer ts.Close()
+
+	request := ChatRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		System:   "You are a pirate.",
+		Options:  &Options{Temperature: float64Ptr(0.2), TopP: float64Ptr(0.9), NumCtx: 8192},
+	}
+	outputChan := make(chan Event, 2)
+	if err := NewOpenAIClient(ts.URL, "").Chat(context.Background(), request, outputChan); err != nil {
+		t.Fatalf("OpenAIClient.Chat() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package ai
+
+import "context"
+
+// Provider streams chat replies from an AI backend.
+type Provider interface {
+	// Chat streams the reply to the last message of request into
+	// outputChan. The stream opens with an EventStart once the backend
+	// accepts the request and ends with an EventDone or EventError event
+	// unless an error is returned.
+	Chat(ctx context.Context, request ChatRequest, outputChan chan<- Event) error
+}
+
+// ChatRequest is a backend-independent chat request.
+type ChatRequest struct {
+	// Model is the model to answer with. Backends that pick their own
+	// model ignore it.
+	Model    string
+	Messages []Message
+	// System replaces the client's system prompt when not empty.
+	System string
+	// Options tune the reply, nil keeps the backend's defaults.
+	Options *Options
+}
+
+// Options tune how the model samples its reply. Nil and zero fields keep
+// the backend's defaults.
+type Options struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	// NumCtx is the context window in tokens, which only Ollama lets the
+	// client choose.
+	NumCtx int `json:"num_ctx,omitempty"`
+}
+
+var (
+	_ Provider = (*Client)(nil)
+	_ Provider = (*OllamaClient)(nil)
+	_ Provider = (*OpenAIClient)(nil)
+)
@@ -0,0 +1,118 @@
+package ai
+
+import "strings"
+
+// sectionMarker switches the stream between thinking and answer output.
+// Line markers only count at the start of a line, so prose such as
+// "Note: THINKING: is a keyword" is left alone.
+type sectionMarker struct {
+	text      string
+	section   EventType
+	lineStart bool
+}
+
+var sectionMarkers = []sectionMarker{
+	{text: "THINKING:", section: EventThinking, lineStart: true},
+	{text: "ANSWER:", section: EventAnswer, lineStart: true},
+	{text: "<think>", section: EventThinking},
+	{text: "</think>", section: EventAnswer},
+}
+
+// sectionSplitter turns the plain text streamed by a chain-of-thought
+// model into thinking and answer events. Text that might be the start of
+// a marker split across chunks is held back until the next Write.
+type sectionSplitter struct {
+	section EventType
+	pending string
+	// lineStart reports whether pending begins at the start of a line
+	lineStart bool
+}
+
+func newSectionSplitter() *sectionSplitter {
+	return &sectionSplitter{section: EventAnswer, lineStart: true}
+}
+
+// Write consumes the next chunk of model output and returns the events
+// that can already be emitted.
+func (splitter *sectionSplitter) Write(chunk string) []Event {
+	var events []Event
+	buffer := splitter.pending + chunk
+	lineStart := splitter.lineStart
+
+	for {
+		index, marker := findSectionMarker(buffer, lineStart)
+		if marker == nil {
+			break
+		}
+		events = appendEvent(events, splitter.section, buffer[:index])
+		splitter.section = marker.section
+		buffer = strings.TrimLeft(buffer[index+len(marker.text):], " ")
+		buffer = strings.TrimPrefix(buffer, "\n")
+		lineStart = true
+	}
+
+	keep := heldBackLength(buffer)
+	emitted := buffer[:len(buffer)-keep]
+	events = appendEvent(events, splitter.section, emitted)
+
+	splitter.pending = buffer[len(buffer)-keep:]
+	if emitted != "" {
+		lineStart = strings.HasSuffix(emitted, "\n")
+	}
+	splitter.lineStart = lineStart
+	return events
+}
+
+// Flush returns whatever text is still held back at the end of a stream.
+func (splitter *sectionSplitter) Flush() []Event {
+	events := appendEvent(nil, splitter.section, splitter.pending)
+	splitter.pending = ""
+	return events
+}
+
+func findSectionMarker(buffer string, lineStart bool) (int, *sectionMarker) {
+	bestIndex := -1
+	var best *sectionMarker
+	for i := range sectionMarkers {
+		marker := &sectionMarkers[i]
+		offset := 0
+		for {
+			index := strings.Index(buffer[offset:], marker.text)
+			if index < 0 {
+				break
+			}
+			index += offset
+			atLineStart := (index == 0 && lineStart) || (index > 0 && buffer[index-1] == '\n')
+			if !marker.lineStart || atLineStart {
+				if bestIndex < 0 || index < bestIndex {
+					bestIndex, best = index, marker
+				}
+				break
+			}
+			offset = index + 1
+		}
+	}
+	return bestIndex, best
+}
+
+// heldBackLength returns the length of the longest suffix of buffer that
+// is a proper prefix of a marker.
+func heldBackLength(buffer string) int {
+	longest := 0
+	for _, marker := range sectionMarkers {
+		for size := len(marker.text) - 1; size > longest; size-- {
+			if strings.HasSuffix(buffer, marker.text[:size]) {
+				longest = size
+				break
+			}
+		}
+	}
+	return longest
+}
+
+func appendEvent(events []Event, section EventType, text string) []Event {
+	if text == "" {
+		return events
+	}
+	return append(events, Event{Type: section, Text: text})
+}
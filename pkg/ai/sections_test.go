@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSectionSplitter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []Event
+	}{
+		{
+			name:   "plain answer",
+			chunks: []string{"Hello ", "world"},
+			want:   []Event{{Type: EventAnswer, Text: "Hello "}, {Type: EventAnswer, Text: "world"}},
+		},
+		{
+			name:   "line markers split across chunks",
+			chunks: []string{"THI", "NKING: step one\nAN", "SWER:\nDone"},
+			want: []Event{
+				{Type: EventThinking, Text: "step one\n"},
+				{Type: EventAnswer, Text: "Done"},
+			},
+		},
+		{
+			name:   "markers inside prose are kept",
+			chunks: []string{"Note: the ANSWER: label is optional"},
+			want:   []Event{{Type: EventAnswer, Text: "Note: the ANSWER: label is optional"}},
+		},
+		{
+			name:   "think tags",
+			chunks: []string{"<think>", "reasoning</thi", "nk>\n\nresult"},
+			want: []Event{
+				{Type: EventThinking, Text: "reasoning"},
+				{Type: EventAnswer, Text: "\nresult"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splitter := newSectionSplitter()
+			var got []Event
+			for _, chunk := range tt.chunks {
+				got = append(got, splitter.Write(chunk)...)
+			}
+			got = append(got, splitter.Flush()...)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sectionSplitter events = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
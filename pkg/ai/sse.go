@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sseEvent is a single dispatched text/event-stream event.
+type sseEvent struct {
+	Event string
+	Data  string
+	ID    string
+	Retry int
+}
+
+// sseReader parses a text/event-stream body as described in the
+// WHATWG HTML "Server-sent events" specification.
+type sseReader struct {
+	reader  *bufio.Reader
+	lastID  string
+	started bool
+}
+
+func newSSEReader(body io.Reader) *sseReader {
+	return &sseReader{reader: bufio.NewReader(body)}
+}
+
+// Next returns the next dispatched event. It returns io.EOF once the
+// stream ends; a trailing event that was never terminated by a blank line
+// is discarded, as the specification requires.
+func (sse *sseReader) Next() (sseEvent, error) {
+	var event sseEvent
+	var data strings.Builder
+	hasData := false
+
+	for {
+		line, err := sse.readLine()
+		if err != nil {
+			return sseEvent{}, err
+		}
+
+		if line == "" {
+			if !hasData {
+				// Blank line without data resets the event type only
+				event = sseEvent{}
+				continue
+			}
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			event.ID = sse.lastID
+			if event.Event == "" {
+				event.Event = "message"
+			}
+			return event, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			// Comment line, used by servers as a keep-alive
+			continue
+		}
+
+		field, value, found := strings.Cut(line, ":")
+		if found {
+			value = strings.TrimPrefix(value, " ")
+		}
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				sse.lastID = value
+			}
+		case "retry":
+			if retry, err := strconv.Atoi(value); err == nil && retry >= 0 {
+				event.Retry = retry
+			}
+		}
+	}
+}
+
+// readLine reads a single line terminated by "\n", "\r\n" or "\r" and
+// strips the terminator and a leading UTF-8 byte order mark. Bytes are
+// kept as-is so multibyte characters are never split.
+func (sse *sseReader) readLine() (string, error) {
+	if !sse.started {
+		sse.started = true
+		if bom, err := sse.reader.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+			_, _ = sse.reader.Discard(3)
+		}
+	}
+
+	var line []byte
+	for {
+		char, err := sse.reader.ReadByte()
+		if err != nil {
+			// An unterminated last line can never be dispatched
+			return "", err
+		}
+		switch char {
+		case '\n':
+			return string(line), nil
+		case '\r':
+			if next, err := sse.reader.ReadByte(); err == nil && next != '\n' {
+				_ = sse.reader.UnreadByte()
+			}
+			return string(line), nil
+		default:
+			line = append(line, char)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSSEReader_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []sseEvent
+	}{
+		{
+			name:   "default event type",
+			stream: "data: hello\n\n",
+			want:   []sseEvent{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:   "named events with id and retry",
+			stream: "event: thinking\nid: 1\nretry: 3000\ndata: hmm\n\nevent: answer\ndata: ok\n\n",
+			want: []sseEvent{
+				{Event: "thinking", Data: "hmm", ID: "1", Retry: 3000},
+				{Event: "answer", Data: "ok", ID: "1"},
+			},
+		},
+		{
+			name:   "multi-line data",
+			stream: "data: first\ndata: second\n\n",
+			want:   []sseEvent{{Event: "message", Data: "first\nsecond"}},
+		},
+		{
+			name:   "comments and blank lines are ignored",
+			stream: ": keep-alive\n\n\ndata: x\n\n",
+			want:   []sseEvent{{Event: "message", Data: "x"}},
+		},
+		{
+			name:   "only one leading space is stripped",
+			stream: "data:  indented\ndata:tight\n\n",
+			want:   []sseEvent{{Event: "message", Data: " indented\ntight"}},
+		},
+		{
+			name:   "crlf and cr line endings",
+			stream: "event: answer\r\ndata: a\r\n\r\ndata: b\r\r",
+			want: []sseEvent{
+				{Event: "answer", Data: "a"},
+				{Event: "message", Data: "b"},
+			},
+		},
+		{
+			name:   "byte order mark and multibyte characters",
+			stream: "\xef\xbb\xbfdata: naïve 日本\n\n",
+			want:   []sseEvent{{Event: "message", Data: "naïve 日本"}},
+		},
+		{
+			name:   "unterminated event is discarded",
+			stream: "data: done\n\ndata: partial",
+			want:   []sseEvent{{Event: "message", Data: "done"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := newSSEReader(strings.NewReader(tt.stream))
+
+			var got []sseEvent
+			for {
+				event, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next() unexpected error: %v", err)
+				}
+				got = append(got, event)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Next() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+type timedProvider struct {
+	provider Provider
+}
+
+// Timed wraps provider so the EventDone of every reply carries how long
+// the reply took in its Usage, next to any token counts from the backend.
+func Timed(provider Provider) Provider {
+	return timedProvider{provider: provider}
+}
+
+func (timed timedProvider) Chat(ctx context.Context, request ChatRequest, outputChan chan<- Event) error {
+	events := make(chan Event)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(events)
+		errChan <- timed.provider.Chat(ctx, request, events)
+	}()
+
+	start := time.Now()
+	var firstToken time.Duration
+	var emitErr error
+	for event := range events {
+		switch event.Type {
+		case EventThinking, EventAnswer:
+			if firstToken == 0 && event.Text != "" {
+				firstToken = time.Since(start)
+			}
+		case EventDone:
+			usage := Usage{}
+			if event.Usage != nil {
+				usage = *event.Usage
+			}
+			usage.TimeToFirstToken = firstToken
+			usage.Duration = time.Since(start)
+			event.Usage = &usage
+		}
+		if emitErr == nil {
+			// Keep draining after a failed send so the provider can return
+			emitErr = emit(ctx, outputChan, event)
+		}
+	}
+
+	if err := <-errChan; err != nil {
+		return err
+	}
+	return emitErr
+}
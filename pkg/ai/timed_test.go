@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeProvider replays events with a delay before each one.
+type fakeProvider struct {
+	events []Event
+	delay  time.Duration
+}
+
+func (fake fakeProvider) Chat(ctx context.Context, request ChatRequest, outputChan chan<- Event) error {
+	for _, event := range fake.events {
+		time.Sleep(fake.delay)
+		if err := emit(ctx, outputChan, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestTimed(t *testing.T) {
+	provider := Timed(fakeProvider{
+		delay: 20 * time.Millisecond,
+		events: []Event{
+			{Type: EventStart},
+			{Type: EventAnswer, Text: "Hi"},
+			{Type: EventAnswer, Text: " there"},
+			{Type: EventDone, Usage: &Usage{PromptTokens: 7, CompletionTokens: 2}},
+		},
+	})
+
+	outputChan := make(chan Event)
+	go func() {
+		_ = provider.Chat(context.Background(), ChatRequest{}, outputChan)
+		close(outputChan)
+	}()
+	var done Event
+	for event := range outputChan {
+		done = event
+	}
+
+	if done.Type != EventDone || done.Usage == nil {
+		t.Fatalf("Timed() last event = %+v, want done with usage", done)
+	}
+	usage := *done.Usage
+	if usage.PromptTokens != 7 || usage.CompletionTokens != 2 {
+		t.Errorf("Timed() usage = %+v, want the backend's token counts", usage)
+	}
+	if usage.TimeToFirstToken < 40*time.Millisecond || usage.TimeToFirstToken >= usage.Duration {
+		t.Errorf("Timed() time to first token = %v, duration = %v", usage.TimeToFirstToken, usage.Duration)
+	}
+}
+
+func TestTimed_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	provider := Timed(fakeProvider{events: []Event{{Type: EventStart}, {Type: EventDone}}})
+
+	// Nobody reads the output, the cancelled context must unblock Chat
+	if err := provider.Chat(ctx, ChatRequest{}, make(chan Event)); err != context.Canceled {
+		t.Errorf("Timed().Chat() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestUsage_TokensPerSecond(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage Usage
+		want  float64
+	}{
+		{name: "generation time", usage: Usage{CompletionTokens: 50, TimeToFirstToken: time.Second, Duration: 3 * time.Second}, want: 25},
+		{name: "no token count", usage: Usage{Duration: time.Second}, want: 0},
+		{name: "no duration", usage: Usage{CompletionTokens: 10}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.usage.TokensPerSecond(); got != tt.want {
+				t.Errorf("TokensPerSecond() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
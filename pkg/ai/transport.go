@@ -0,0 +1,287 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrStreamIdle is returned when a backend stops sending data for longer
+// than Transport.IdleTimeout.
+var ErrStreamIdle = errors.New("stream idle timeout")
+
+// ErrResponseTimeout is returned when a backend accepts a request but
+// sends no response within Transport.IdleTimeout. It is not retried, as a
+// backend that hung once usually hangs again.
+var ErrResponseTimeout = errors.New("timed out waiting for the response")
+
+// Transport controls how the clients connect to a backend. The zero value
+// has no timeouts and never retries.
+type Transport struct {
+	// ConnectTimeout bounds dialing and the TLS handshake.
+	ConnectTimeout time.Duration
+	// IdleTimeout bounds the wait for the response and every gap between
+	// two reads of the stream. A request that times out waiting for the
+	// response is not retried.
+	IdleTimeout time.Duration
+	// MaxRetries is how many times a request is repeated after a
+	// connection error or a 429/5xx status. Nothing is retried once the
+	// response has started streaming.
+	MaxRetries int
+	// BaseBackoff is the delay before the first retry. It doubles with
+	// every attempt, with jitter, up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultTransport returns the settings used by the New*Client
+// constructors. The idle timeout is generous because reasoning models can
+// take a while to load before the first token.
+func DefaultTransport() Transport {
+	return Transport{
+		ConnectTimeout: 10 * time.Second,
+		IdleTimeout:    2 * time.Minute,
+		MaxRetries:     3,
+		BaseBackoff:    500 * time.Millisecond,
+		MaxBackoff:     8 * time.Second,
+	}
+}
+
+// HTTPError is returned when a backend answers with a non-2xx status.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	// Body is the start of the response body, which usually explains
+	// the failure.
+	Body string
+	// retryAfter is the delay requested by a Retry-After header.
+	retryAfter time.Duration
+}
+
+func (httpErr *HTTPError) Error() string {
+	if httpErr.Body == "" {
+		return fmt.Sprintf("unexpected status %s", httpErr.Status)
+	}
+	return fmt.Sprintf("unexpected status %s: %s", httpErr.Status, httpErr.Body)
+}
+
+// Retryable reports whether the request may succeed if sent again.
+func (httpErr *HTTPError) Retryable() bool {
+	return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+}
+
+// do sends req, retrying connection errors and retryable statuses with
+// backoff. The returned body enforces IdleTimeout on every read.
+func (transport Transport) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	client := transport.client()
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("error rewinding request body: %w", err)
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := transport.send(client, attemptReq)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if !retryable(err) || attempt >= transport.MaxRetries {
+			return nil, err
+		}
+
+		delay := transport.backoff(attempt)
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.retryAfter > 0 {
+			delay = httpErr.retryAfter
+			if transport.MaxBackoff > 0 && delay > transport.MaxBackoff {
+				delay = transport.MaxBackoff
+			}
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// clients holds an *http.Client per connect timeout, shared by every
+// request with that setting so connections are kept alive between them.
+var clients sync.Map
+
+// client returns the shared client for the connect timeout of transport.
+// The wait for the response is bounded by send.
+func (transport Transport) client() *http.Client {
+	key := transport.ConnectTimeout
+	if client, ok := clients.Load(key); ok {
+		return client.(*http.Client)
+	}
+	dialer := &net.Dialer{Timeout: transport.ConnectTimeout}
+	client, _ := clients.LoadOrStore(key, &http.Client{Transport: &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: transport.ConnectTimeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}})
+	return client.(*http.Client)
+}
+
+// retryable reports whether a request that failed with err may succeed
+// if sent again: a 429/5xx status, or a connection that was refused, reset,
+// dropped or timed out. Errors such as a bad certificate, a malformed URL
+// or an unknown host are not retried.
+func retryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Retryable()
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// Every error of http.Client.Do is a *url.Error, which is a net.Error
+	// itself, so only look at what it wraps
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		// A TLS alert from the server is reported as a "remote error"
+		return opErr.Op != "remote error"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// send makes a single attempt, turning non-2xx responses into *HTTPError
+// and a response that takes longer than IdleTimeout into
+// ErrResponseTimeout.
+func (transport Transport) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	var timedOut atomic.Bool
+	var timer *time.Timer
+	if transport.IdleTimeout > 0 {
+		timer = time.AfterFunc(transport.IdleTimeout, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if timer != nil {
+		timer.Stop()
+	}
+	if timedOut.Load() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, ErrResponseTimeout
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer cancel()
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		httpErr := &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(body)),
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			httpErr.retryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, httpErr
+	}
+
+	resp.Body = newIdleBody(resp.Body, transport.IdleTimeout, cancel)
+	return resp, nil
+}
+
+// backoff returns the delay before retry number attempt+1: an exponential
+// step with equal jitter, so concurrent clients do not retry in lockstep.
+func (transport Transport) backoff(attempt int) time.Duration {
+	if transport.BaseBackoff <= 0 {
+		return 0
+	}
+	delay := transport.BaseBackoff << min(attempt, 30)
+	if transport.MaxBackoff > 0 && (delay > transport.MaxBackoff || delay <= 0) {
+		delay = transport.MaxBackoff
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// idleBody cancels the request when no data arrives for timeout, and
+// reports ErrStreamIdle instead of the cancellation.
+type idleBody struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+	cancel   context.CancelFunc
+}
+
+func newIdleBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleBody {
+	idle := &idleBody{body: body, timeout: timeout, cancel: cancel}
+	if timeout > 0 {
+		idle.timer = time.AfterFunc(timeout, func() {
+			idle.timedOut.Store(true)
+			cancel()
+		})
+	}
+	return idle
+}
+
+func (idle *idleBody) Read(p []byte) (int, error) {
+	n, err := idle.body.Read(p)
+	if idle.timedOut.Load() {
+		return n, ErrStreamIdle
+	}
+	if idle.timer != nil && n > 0 {
+		idle.timer.Reset(idle.timeout)
+	}
+	return n, err
+}
+
+func (idle *idleBody) Close() error {
+	if idle.timer != nil {
+		idle.timer.Stop()
+	}
+	err := idle.body.Close()
+	idle.cancel()
+	return err
+}
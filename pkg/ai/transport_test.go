@@ -0,0 +1,309 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fastTransport retries without noticeable delays.
+var fastTransport = Transport{
+	ConnectTimeout: time.Second,
+	IdleTimeout:    time.Second,
+	MaxRetries:     2,
+	BaseBackoff:    time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+func TestTransport_Do(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		retryAfter   string
+		wantAttempts int32
+		wantStatus   int
+	}{
+		{
+			name:         "success",
+			statuses:     []int{http.StatusOK},
+			wantAttempts: 1,
+		},
+		{
+			name:         "retries 503 until success",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries 429 honouring Retry-After",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "30",
+			wantAttempts: 2,
+		},
+		{
+			name:         "gives up after MaxRetries",
+			statuses:     []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK},
+			wantAttempts: 3,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		{
+			name:         "does not retry client errors",
+			statuses:     []int{http.StatusBadRequest, http.StatusOK},
+			wantAttempts: 1,
+			wantStatus:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := attempts.Add(1)
+				if body, _ := io.ReadAll(r.Body); string(body) != `{"message":"Hello"}` {
+					t.Errorf("attempt %d body = %q, want the original request", attempt, body)
+				}
+				status := tt.statuses[attempt-1]
+				if status != http.StatusOK {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					http.Error(w, "server says no", status)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}))
+			defer ts.Close()
+
+			req, err := http.NewRequest("POST", ts.URL, bytes.NewBufferString(`{"message":"Hello"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			resp, err := fastTransport.do(req)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Transport.do() took %v, want backoff capped by MaxBackoff", elapsed)
+			}
+
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("Transport.do() made %d attempts, want %d", got, tt.wantAttempts)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("Transport.do() unexpected error: %v", err)
+				}
+				defer resp.Body.Close()
+				if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+					t.Errorf("Transport.do() body = %q, want ok", body)
+				}
+				return
+			}
+
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("Transport.do() error = %v, want *HTTPError", err)
+			}
+			if httpErr.StatusCode != tt.wantStatus || httpErr.Body != "server says no" {
+				t.Errorf("Transport.do() error = %+v, want status %d with the body", httpErr, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTransport_Do_ConnectionError(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			// Drop the connection without answering
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	req, _ := http.NewRequest("POST", ts.URL, bytes.NewBufferString("{}"))
+	resp, err := fastTransport.do(req)
+	if err != nil {
+		t.Fatalf("Transport.do() unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Transport.do() made %d attempts, want 2", got)
+	}
+}
+
+func TestTransport_Do_IdleTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	transport := fastTransport
+	transport.IdleTimeout = 50 * time.Millisecond
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err := transport.do(req)
+	if err != nil {
+		t.Fatalf("Transport.do() unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if !errors.Is(err, ErrStreamIdle) {
+		t.Errorf("reading body error = %v, want %v", err, ErrStreamIdle)
+	}
+	if string(body) != "first" {
+		t.Errorf("body = %q, want the data sent before the stall", body)
+	}
+}
+
+func TestTransport_Do_ResponseTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	transport := fastTransport
+	transport.IdleTimeout = 50 * time.Millisecond
+	req, _ := http.NewRequest("POST", ts.URL, bytes.NewBufferString("{}"))
+	if _, err := transport.do(req); !errors.Is(err, ErrResponseTimeout) {
+		t.Errorf("Transport.do() error = %v, want %v", err, ErrResponseTimeout)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Transport.do() made %d attempts, want a hung backend not retried", got)
+	}
+}
+
+func TestTransport_Do_Cancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	transport := fastTransport
+	transport.BaseBackoff = time.Hour
+	transport.MaxBackoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL, nil)
+
+	if _, err := transport.do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Transport.do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestTransport_Backoff(t *testing.T) {
+	transport := Transport{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		want *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if got := transport.backoff(attempt); got < want/2 || got > want {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, got, want/2, want)
+			}
+		}
+	}
+	if got := (Transport{}).backoff(3); got != 0 {
+		t.Errorf("zero Transport backoff = %v, want 0", got)
+	}
+}
+
+func TestTransport_Do_NotRetried(t *testing.T) {
+	var connections atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	// The test server's certificate is not trusted by the transport
+	req, _ := http.NewRequest("POST", ts.URL, bytes.NewBufferString("{}"))
+	if _, err := fastTransport.do(req); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("Transport.do() error = %v, want a certificate error", err)
+	}
+	if got := connections.Load(); got != 1 {
+		t.Errorf("Transport.do() made %d connections, want 1", got)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	_, urlErr := http.NewRequest("GET", "http://[::1", nil)
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "503", err: &HTTPError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "429", err: &HTTPError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "404", err: &HTTPError{StatusCode: http.StatusNotFound}},
+		{name: "refused", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, want: true},
+		{name: "reset", err: &url.Error{Op: "Post", Err: syscall.ECONNRESET}, want: true},
+		{name: "dropped", err: &url.Error{Op: "Post", Err: io.EOF}, want: true},
+		{name: "timeout", err: &url.Error{Op: "Post", Err: &net.DNSError{Err: "timeout", IsTimeout: true}}, want: true},
+		{name: "unknown host", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}}},
+		{name: "tls alert", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "remote error", Err: errors.New("tls: handshake failure")}}},
+		{name: "bad url", err: urlErr},
+		{name: "unsupported scheme", err: &url.Error{Op: "Post", Err: errors.New(`unsupported protocol scheme "ftp"`)}},
+	}
+
+	for _, tt := range tests {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("retryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTransport_Do_ReusesConnections(t *testing.T) {
+	var connections atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("POST", ts.URL, bytes.NewBufferString("{}"))
+		resp, err := fastTransport.do(req)
+		if err != nil {
+			t.Fatalf("Transport.do() unexpected error: %v", err)
+		}
+		_, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	if got := connections.Load(); got != 1 {
+		t.Errorf("3 requests opened %d connections, want 1", got)
+	}
+}
@@ -0,0 +1,279 @@
+package attach
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andreivisan/quantum_cli/pkg/units"
+)
+
+const (
+	// DefaultMaxFileSize is the largest file a Loader reads.
+	DefaultMaxFileSize = 256_000
+	// DefaultMaxTotalSize caps everything attached to a single message.
+	DefaultMaxTotalSize = 1_000_000
+)
+
+// File is a text file attached to a chat message.
+type File struct {
+	// Path is relative to the loader's root when the file lies inside it.
+	Path     string `json:"path"`
+	Language string `json:"language,omitempty"`
+	Content  string `json:"content"`
+}
+
+// Loader reads files for attaching, skipping what .gitignore excludes when
+// expanding globs and directories.
+type Loader struct {
+	Root         string
+	MaxFileSize  int64
+	MaxTotalSize int64
+	ignore       *ignoreMatcher
+}
+
+func NewLoader(root string) *Loader {
+	return &Loader{
+		Root:         root,
+		MaxFileSize:  DefaultMaxFileSize,
+		MaxTotalSize: DefaultMaxTotalSize,
+		ignore:       newIgnoreMatcher(root),
+	}
+}
+
+// Load reads the files named by pattern, which is a path, a directory or
+// a glob. A file named explicitly is read even if ignored; files found
+// through a glob or a directory are skipped when ignored or binary.
+func (loader *Loader) Load(pattern string) ([]File, error) {
+	pattern = loader.absolute(pattern)
+	info, err := os.Stat(pattern)
+	switch {
+	case err == nil && !info.IsDir():
+		file, err := loader.read(pattern, info)
+		if err != nil {
+			return nil, err
+		}
+		return []File{file}, nil
+	case err == nil:
+		return loader.loadAll(loader.walk(pattern))
+	}
+
+	matches, globErr := filepath.Glob(pattern)
+	if globErr != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, globErr)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %s", loader.relative(pattern))
+	}
+	var paths []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			paths = append(paths, loader.walk(match)...)
+		} else if !loader.ignored(match, false) {
+			paths = append(paths, match)
+		}
+	}
+	return loader.loadAll(paths)
+}
+
+// loadAll reads paths, silently skipping binary and oversized files as
+// they were not named explicitly.
+func (loader *Loader) loadAll(paths []string) ([]File, error) {
+	var files []File
+	var total int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		file, err := loader.read(path, info)
+		if errors.Is(err, errBinary) || errors.Is(err, errTooLarge) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(file.Content))
+		if loader.MaxTotalSize > 0 && total > loader.MaxTotalSize {
+			return nil, fmt.Errorf("attachments exceed %s, narrow the pattern", units.FormatSize(loader.MaxTotalSize))
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no text files found")
+	}
+	return files, nil
+}
+
+var (
+	errBinary   = errors.New("binary file")
+	errTooLarge = errors.New("file too large")
+)
+
+func (loader *Loader) read(path string, info fs.FileInfo) (File, error) {
+	rel := loader.relative(path)
+	if loader.MaxFileSize > 0 && info.Size() > loader.MaxFileSize {
+		return File{}, fmt.Errorf("%s is %s, the limit is %s: %w", rel, units.FormatSize(info.Size()), units.FormatSize(loader.MaxFileSize), errTooLarge)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read %s: %v", rel, err)
+	}
+	if IsBinary(data) {
+		return File{}, fmt.Errorf("%s is not a text file: %w", rel, errBinary)
+	}
+	return File{Path: rel, Language: Language(path), Content: string(data)}, nil
+}
+
+// walk lists the files under dir that are not ignored, in lexical order.
+func (loader *Loader) walk(dir string) []string {
+	var paths []string
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path != dir && loader.ignored(path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	sort.Strings(paths)
+	return paths
+}
+
+func (loader *Loader) ignored(path string, isDir bool) bool {
+	rel, err := filepath.Rel(loader.Root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	return loader.ignore.Ignored(filepath.ToSlash(rel), isDir)
+}
+
+// absolute resolves path against the home directory and the root.
+func (loader *Loader) absolute(path string) string {
+	path = expandHome(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(loader.Root, path)
+	}
+	return path
+}
+
+func (loader *Loader) relative(path string) string {
+	rel, err := filepath.Rel(loader.Root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// IsBinary reports whether data looks like something other than UTF-8
+// text, judging by a NUL byte or invalid UTF-8 in its first 8 KB.
+func IsBinary(data []byte) bool {
+	sample := data
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+		// Do not count a rune cut in half by the sample as invalid
+		for i := 0; i < utf8.UTFMax && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(sample)
+}
+
+// Inline appends files to text as fenced blocks headed by their path.
+func Inline(text string, files []File) string {
+	if len(files) == 0 {
+		return text
+	}
+	var builder strings.Builder
+	builder.WriteString(text)
+	for _, file := range files {
+		content := strings.TrimRight(file.Content, "\n")
+		fence := "```"
+		for strings.Contains(content, fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(&builder, "\n\n`%s`:\n%s%s\n%s\n%s", file.Path, fence, file.Language, content, fence)
+	}
+	return builder.String()
+}
+
+// Mentions returns the paths of the @path mentions in text that name an
+// existing file or directory, or a glob matching one. A mention is a word
+// starting with @, so e-mail addresses are not picked up, and one naming
+// nothing, such as @team, is left as text.
+func (loader *Loader) Mentions(text string) []string {
+	var paths []string
+	for _, word := range strings.Fields(text) {
+		if len(word) < 2 || word[0] != '@' {
+			continue
+		}
+		path := strings.TrimRight(word[1:], ",.;:!?)")
+		if path != "" && loader.exists(path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// exists reports whether pattern names a path or matches one.
+func (loader *Loader) exists(pattern string) bool {
+	pattern = loader.absolute(pattern)
+	if _, err := os.Stat(pattern); err == nil {
+		return true
+	}
+	matches, _ := filepath.Glob(pattern)
+	return len(matches) > 0
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// languages maps file extensions to Markdown code block languages.
+var languages = map[string]string{
+	".go": "go", ".py": "python", ".js": "javascript", ".jsx": "jsx",
+	".ts": "typescript", ".tsx": "tsx", ".java": "java", ".kt": "kotlin",
+	".rs": "rust", ".c": "c", ".h": "c", ".cpp": "cpp", ".cc": "cpp",
+	".hpp": "cpp", ".cs": "csharp", ".rb": "ruby", ".php": "php",
+	".swift": "swift", ".scala": "scala", ".sh": "bash", ".bash": "bash",
+	".zsh": "zsh", ".fish": "fish", ".ps1": "powershell", ".sql": "sql",
+	".html": "html", ".css": "css", ".scss": "scss", ".json": "json",
+	".yaml": "yaml", ".yml": "yaml", ".toml": "toml", ".xml": "xml",
+	".md": "markdown", ".proto": "protobuf", ".lua": "lua", ".r": "r",
+	".dart": "dart", ".ex": "elixir", ".exs": "elixir", ".erl": "erlang",
+	".hs": "haskell", ".clj": "clojure", ".vue": "vue", ".svelte": "svelte",
+	".tf": "hcl", ".ini": "ini", ".env": "bash",
+}
+
+// Language guesses the code block language of path from its name.
+func Language(path string) string {
+	switch base := filepath.Base(path); base {
+	case "Dockerfile":
+		return "dockerfile"
+	case "Makefile":
+		return "makefile"
+	}
+	return languages[strings.ToLower(filepath.Ext(path))]
+}
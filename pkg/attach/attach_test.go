@@ -0,0 +1,165 @@
+package attach
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files under a temporary root and returns it.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLoader_Load(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".gitignore":            "*.log\nbuild/\n!keep.log\n",
+		"main.go":               "package main\n",
+		"README.md":             "# Demo\n",
+		"debug.log":             "noise\n",
+		"keep.log":              "kept\n",
+		"build/out.go":          "package build\n",
+		"pkg/util/util.go":      "package util\n",
+		"pkg/util/.gitignore":   "generated.go\n",
+		"pkg/util/generated.go": "package util\n",
+		"pkg/logo.png":          "\x89PNG\x00\x00",
+		".git/config":           "[core]\n",
+	})
+
+	tests := []struct {
+		name      string
+		pattern   string
+		wantPaths []string
+		wantErr   string
+	}{
+		{name: "single file", pattern: "main.go", wantPaths: []string{"main.go"}},
+		{name: "explicit ignored file", pattern: "debug.log", wantPaths: []string{"debug.log"}},
+		{name: "glob skips ignored", pattern: "*.log", wantPaths: []string{"keep.log"}},
+		{name: "glob of go files", pattern: "*.go", wantPaths: []string{"main.go"}},
+		{name: "directory skips ignored and binary", pattern: ".", wantPaths: []string{".gitignore", "README.md", "keep.log", "main.go", "pkg/util/.gitignore", "pkg/util/util.go"}},
+		{name: "explicit binary file", pattern: "pkg/logo.png", wantErr: "not a text file"},
+		{name: "missing file", pattern: "nope/*.go", wantErr: "no files match nope/*.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := NewLoader(root).Load(tt.pattern)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load(%q) error = %v, want %q", tt.pattern, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load(%q) unexpected error: %v", tt.pattern, err)
+			}
+			var paths []string
+			for _, file := range files {
+				paths = append(paths, file.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("Load(%q) = %v, want %v", tt.pattern, paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestLoader_Load_Limits(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"a.txt":   strings.Repeat("a", 60),
+		"b.txt":   strings.Repeat("b", 60),
+		"big.txt": strings.Repeat("c", 200),
+	})
+	loader := NewLoader(root)
+	loader.MaxFileSize = 100
+	loader.MaxTotalSize = 100
+
+	if _, err := loader.Load("big.txt"); err == nil || !strings.Contains(err.Error(), "the limit is 100 B") {
+		t.Errorf("Load(big.txt) error = %v, want the file size limit", err)
+	}
+	if _, err := loader.Load("*.txt"); err == nil || !strings.Contains(err.Error(), "attachments exceed") {
+		t.Errorf("Load(*.txt) error = %v, want the total size limit", err)
+	}
+	if files, err := loader.Load("a.txt"); err != nil || files[0].Content != strings.Repeat("a", 60) {
+		t.Errorf("Load(a.txt) = %v, %v", files, err)
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{name: "text", data: []byte("hello, naïve world\n"), want: false},
+		{name: "nul byte", data: []byte("ab\x00cd"), want: true},
+		{name: "invalid utf-8", data: []byte{0xff, 0xfe, 0x41}, want: true},
+		{name: "rune cut by the sample", data: []byte(strings.Repeat("a", 7999) + "é"), want: false},
+		{name: "empty", data: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinary(tt.data); got != tt.want {
+				t.Errorf("IsBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInline(t *testing.T) {
+	files := []File{
+		{Path: "main.go", Language: "go", Content: "package main\n"},
+		{Path: "README.md", Language: "markdown", Content: "```sh\nmake\n```\n"},
+	}
+	want := "review these\n\n`main.go`:\n```go\npackage main\n```" +
+		"\n\n`README.md`:\n````markdown\n```sh\nmake\n```\n````"
+	if got := Inline("review these", files); got != want {
+		t.Errorf("Inline() = %q, want %q", got, want)
+	}
+}
+
+func TestLoader_Mentions(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"main.go":          "package main\n",
+		"pkg/util/util.go": "package util\n",
+	})
+	loader := NewLoader(root)
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "compare @main.go with @pkg/util/util.go, mail me@example.com", want: []string{"main.go", "pkg/util/util.go"}},
+		{text: "summarize @pkg and @*.go", want: []string{"pkg", "*.go"}},
+		{text: "what does @Override do? ping @team", want: nil},
+		{text: "read @main.go, not @missing.go", want: []string{"main.go"}},
+		{text: "a lone @ sign", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := loader.Mentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Mentions(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	for path, want := range map[string]string{"main.go": "go", "app.TSX": "tsx", "Dockerfile": "dockerfile", "notes": ""} {
+		if got := Language(path); got != want {
+			t.Errorf("Language(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
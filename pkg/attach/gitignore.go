@@ -0,0 +1,141 @@
+package attach
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one pattern line of a .gitignore file.
+type ignoreRule struct {
+	// base is the slash-separated directory of the .gitignore, relative to
+	// the root, "" for the root itself.
+	base     string
+	segments []string
+	negate   bool
+	dirOnly  bool
+	// anchored patterns contain a slash and match from base only.
+	anchored bool
+}
+
+// ignoreMatcher answers whether a path is excluded by the .gitignore files
+// found from the root down.
+type ignoreMatcher struct {
+	root  string
+	rules []ignoreRule
+	// loaded remembers the directories whose .gitignore was read.
+	loaded map[string]bool
+}
+
+func newIgnoreMatcher(root string) *ignoreMatcher {
+	matcher := &ignoreMatcher{root: root, loaded: map[string]bool{}}
+	matcher.load(filepath.Join(root, ".git", "info", "exclude"), "")
+	return matcher
+}
+
+// Ignored reports whether rel, a slash-separated path relative to the
+// root, is excluded. Like git, the last matching rule wins and a file in an
+// excluded directory stays excluded.
+func (matcher *ignoreMatcher) Ignored(rel string, isDir bool) bool {
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		dir := strings.Join(parts[:i], "/")
+		if !matcher.loaded[dir] {
+			matcher.loaded[dir] = true
+			matcher.load(filepath.Join(matcher.root, filepath.FromSlash(dir), ".gitignore"), dir)
+		}
+	}
+
+	for i := 1; i <= len(parts); i++ {
+		partial := strings.Join(parts[:i], "/")
+		if parts[i-1] == ".git" || matcher.match(partial, isDir || i < len(parts)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (matcher *ignoreMatcher) match(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range matcher.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.matches(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func (matcher *ignoreMatcher) load(file string, base string) {
+	handle, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer handle.Close()
+
+	scanner := bufio.NewScanner(handle)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		rule.segments = strings.Split(line, "/")
+		matcher.rules = append(matcher.rules, rule)
+	}
+}
+
+func (rule ignoreRule) matches(rel string) bool {
+	if rule.base != "" {
+		if !strings.HasPrefix(rel, rule.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, rule.base+"/")
+	}
+	parts := strings.Split(rel, "/")
+	if !rule.anchored {
+		// A pattern without a slash matches the name at any depth
+		matched, _ := path.Match(rule.segments[0], parts[len(parts)-1])
+		return matched
+	}
+	return matchSegments(rule.segments, parts)
+}
+
+// matchSegments matches path segments against pattern segments, where
+// "**" stands for any number of directories.
+func matchSegments(pattern []string, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], parts[0])
+	return matched && matchSegments(pattern[1:], parts[1:])
+}
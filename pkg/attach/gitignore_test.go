@@ -0,0 +1,36 @@
+package attach
+
+import "testing"
+
+func TestIgnoreMatcher_Ignored(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".gitignore":     "/vendor\ndocs/**/*.pdf\nnode_modules/\n*.tmp\n!important.tmp\n",
+		"web/.gitignore": "dist\n",
+	})
+	matcher := newIgnoreMatcher(root)
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "vendor", isDir: true, want: true},
+		{path: "vendor/lib.go", want: true},
+		{path: "pkg/vendor/lib.go", want: false},
+		{path: "docs/a/b/guide.pdf", want: true},
+		{path: "docs/guide.pdf", want: true},
+		{path: "guide.pdf", want: false},
+		{path: "web/node_modules/react/index.js", want: true},
+		{path: "node_modules", isDir: false, want: false},
+		{path: "cache/x.tmp", want: true},
+		{path: "important.tmp", want: false},
+		{path: "web/dist/app.js", want: true},
+		{path: "dist/app.js", want: false},
+		{path: ".git/HEAD", want: true},
+	}
+	for _, tt := range tests {
+		if got := matcher.Ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -2,9 +2,17 @@ package chat
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/attach"
+	"github.com/andreivisan/quantum_cli/pkg/rag"
+	"github.com/andreivisan/quantum_cli/pkg/session"
+	"github.com/andreivisan/quantum_cli/pkg/units"
 	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -13,20 +21,24 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-type OutputMsg string
+// OutputMsg is an event of the reply being streamed. The UI keeps
+// listening for them until the reply's EventDone or EventError.
+type OutputMsg ai.Event
 
-type OutputDoneMsg struct{}
-
-type Message struct {
-	Role    string
-	Content string
-}
+// Message is a chat turn. It is the saved session message, so what is on
+// screen is exactly what gets persisted.
+type Message = session.Message
 
 type Styles struct {
-	BorderColor lipgloss.Color
-	InputStyle  lipgloss.Style
-	PromptStyle lipgloss.Style
-	ChatStyle   lipgloss.Style
+	BorderColor   lipgloss.Color
+	InputStyle    lipgloss.Style
+	PromptStyle   lipgloss.Style
+	ChatStyle     lipgloss.Style
+	NoticeStyle   lipgloss.Style
+	StatusStyle   lipgloss.Style
+	ThinkingStyle lipgloss.Style
+	ErrorStyle    lipgloss.Style
+	ChipStyle     lipgloss.Style
 }
 
 func DefaultStyles() *Styles {
@@ -50,27 +62,89 @@ func DefaultStyles() *Styles {
 		PaddingBottom(0).
 		MarginTop(0).
 		MarginBottom(1)
+	styles.NoticeStyle = lipgloss.NewStyle().
+		PaddingLeft(2).
+		Italic(true).
+		Foreground(lipgloss.Color("240"))
+	styles.StatusStyle = lipgloss.NewStyle().
+		PaddingLeft(1).
+		Foreground(lipgloss.Color("240"))
+	styles.ThinkingStyle = lipgloss.NewStyle().
+		MarginLeft(2).
+		PaddingLeft(1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(lipgloss.Color("238")).
+		Foreground(lipgloss.Color("243")).
+		Italic(true)
+	styles.ErrorStyle = lipgloss.NewStyle().
+		MarginLeft(2).
+		Padding(0, 1).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("160")).
+		Foreground(lipgloss.Color("203"))
+	styles.ChipStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		MarginRight(1).
+		Background(lipgloss.Color("236")).
+		Foreground(lipgloss.Color("36"))
 	return styles
 }
 
 type Model struct {
 	viewport         viewport.Model
 	textarea         textarea.Model
-	userInputChan    chan<- string
-	ollamaOutputChan <-chan string
+	userInputChan    chan<- ai.ChatRequest
+	ollamaOutputChan <-chan ai.Event
+	stopChan         chan<- struct{}
 	messages         []Message
 	err              error
 	styles           *Styles
-	waiting          bool
+	streaming        bool
 	mySpinner        spinner.Model
 	ready            bool
 	width            int
 	height           int
 	renderer         *glamour.TermRenderer
 	quitting         bool
+	model            string
+	baseModel        string // the model without a profile naming one
+	listModels       ModelLister
+	picker           list.Model
+	picking          bool
+	notice           string
+	store            *session.Store
+	session          *session.Session
+	// thinkingToggled holds the messages whose reasoning block the user
+	// expanded or collapsed, overriding the default
+	thinkingToggled map[int]bool
+	// selected is the message whose reasoning ctrl+t toggles, -1 for the
+	// latest one
+	selected int
+	// attachments are sent with the next message
+	attachments []attach.File
+	loader      *attach.Loader
+	memory      MemorySwitch
+	profiles    []Profile
+	profile     Profile
 }
 
-func New(userInputChan chan<- string, ollamaOutputChan <-chan string) *Model {
+// Profile is a persona the user can switch to with /profile.
+type Profile struct {
+	Name   string
+	System string
+	// Model, if not empty, replaces the chat's model.
+	Model   string
+	Options *ai.Options
+}
+
+// MemorySwitch turns recalling earlier sessions on and off.
+type MemorySwitch interface {
+	SetEnabled(enabled bool)
+	Enabled() bool
+}
+
+func New(userInputChan chan<- ai.ChatRequest, ollamaOutputChan <-chan ai.Event, stopChan chan<- struct{}) *Model {
 	textarea := textarea.New()
 	textarea.Placeholder = "Send a message..."
 	textarea.Focus()
@@ -99,22 +173,96 @@ func New(userInputChan chan<- string, ollamaOutputChan <-chan string) *Model {
 	)
 
 	styles := DefaultStyles()
+	workDir, _ := os.Getwd()
 
 	return &Model{
 		textarea:         textarea,
 		viewport:         viewport,
 		userInputChan:    userInputChan,
 		ollamaOutputChan: ollamaOutputChan,
+		stopChan:         stopChan,
 		messages:         []Message{},
 		err:              nil,
 		styles:           styles,
-		waiting:          false,
+		streaming:        false,
 		mySpinner:        mySpinner,
 		ready:            true,
 		width:            0,
 		height:           0,
 		renderer:         renderer,
 		quitting:         false,
+		thinkingToggled:  map[int]bool{},
+		selected:         -1,
+		loader:           attach.NewLoader(workDir),
+	}
+}
+
+// SetTheme switches the Markdown rendering style of AI messages. Theme is
+// a glamour style name such as "dark", "light" or "dracula"; "auto" or an
+// empty theme picks one from the terminal background.
+func (myModel *Model) SetTheme(theme string) {
+	renderer, err := glamour.NewTermRenderer(
+		ThemeOption(theme),
+		glamour.WithWordWrap(myModel.viewport.Width),
+	)
+	if err != nil {
+		myModel.err = err
+		return
+	}
+	myModel.renderer = renderer
+}
+
+// ThemeOption returns the glamour option for a configured theme name.
+func ThemeOption(theme string) glamour.TermRendererOption {
+	if theme == "" || theme == "auto" {
+		return glamour.WithAutoStyle()
+	}
+	return glamour.WithStandardStyle(theme)
+}
+
+// SetModel sets the model used for the following turns.
+func (myModel *Model) SetModel(model string) {
+	myModel.model = model
+	myModel.baseModel = model
+}
+
+// SetBaseModel sets the model /profile switches back to for profiles
+// without one, when the chat starts with the model of a profile.
+func (myModel *Model) SetBaseModel(model string) {
+	myModel.baseModel = model
+}
+
+// SetModelLister enables the model switcher, which offers the models
+// returned by lister. Without one the switcher is unavailable, as for
+// backends that pick their own model.
+func (myModel *Model) SetModelLister(lister ModelLister) {
+	myModel.listModels = lister
+}
+
+// SetProfiles offers profiles to /profile and answers with the one called
+// active, if any.
+func (myModel *Model) SetProfiles(profiles []Profile, active string) {
+	myModel.profiles = profiles
+	for _, profile := range profiles {
+		if profile.Name == active {
+			myModel.profile = profile
+		}
+	}
+}
+
+// SetMemory lets /memory switch recalling earlier sessions on and off.
+func (myModel *Model) SetMemory(memory MemorySwitch) {
+	myModel.memory = memory
+}
+
+// SetSession saves the conversation into sess in store after every turn.
+// Messages already in sess are loaded, so a saved session can be resumed.
+func (myModel *Model) SetSession(store *session.Store, sess *session.Session) {
+	myModel.store = store
+	myModel.session = sess
+	myModel.messages = append([]Message{}, sess.Messages...)
+	if len(myModel.messages) > 0 {
+		myModel.rebuildViewport()
 	}
 }
 
@@ -125,13 +273,29 @@ func (model Model) Init() tea.Cmd {
 func (myModel *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && myModel.picking {
+		return myModel.updatePicker(keyMsg)
+	}
+
 	switch msg := msg.(type) {
+	case modelsLoadedMsg:
+		if msg.err != nil {
+			myModel.notice = fmt.Sprintf("could not list models: %v", msg.err)
+			return myModel, nil
+		}
+		if len(msg.options) == 0 {
+			myModel.notice = "no models installed, pull one with 'qcli models pull'"
+			return myModel, nil
+		}
+		myModel.notice = ""
+		myModel.picker = newModelPicker(msg.options, myModel.model, myModel.width-4, myModel.viewport.Height)
+		myModel.picking = true
+		myModel.textarea.Blur()
+		return myModel, nil
+
 	case tea.WindowSizeMsg:
-		headerHeight := 1
-		inputTextHeight := 6 // textarea height + margins
-		viewportHeight := msg.Height - headerHeight - inputTextHeight - 3
 		if !myModel.ready {
-			myModel.viewport = viewport.New(msg.Width, viewportHeight)
+			myModel.viewport = viewport.New(msg.Width, 0)
 			myModel.viewport.HighPerformanceRendering = true
 			myModel.viewport.MouseWheelEnabled = true
 			myModel.viewport.SetContent(`Type a message and press Enter to send.`)
@@ -139,49 +303,83 @@ func (myModel *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		myModel.width = msg.Width
 		myModel.height = msg.Height
-		myModel.viewport.Width = myModel.width - 4
-		myModel.viewport.Height = viewportHeight
-		myModel.textarea.SetWidth(myModel.width - 2)
-		myModel.textarea.SetHeight(4)
+		myModel.resize()
 
 	case tea.KeyMsg:
-		if myModel.waiting {
-			// Ignore most key presses while waiting
+		if myModel.streaming {
+			// Ignore most key presses until the reply ends, esc stops it
 			switch msg.String() {
 			case "ctrl+c":
-				myModel.quitting = true
-				return myModel, tea.Quit
+				return myModel.quit()
+			case "esc":
+				myModel.stopGeneration()
+				return myModel, nil
+			case "ctrl+t", "alt+up", "alt+down":
+				myModel.handleThinkingKey(msg.String())
+				return myModel, nil
 			default:
 				return myModel, nil
 			}
 		}
 		switch msg.String() {
 		case "esc", "ctrl+c":
-			myModel.quitting = true
-			fmt.Println(myModel.textarea.Value())
-			return myModel, tea.Quit
+			return myModel.quit()
+		case "ctrl+o":
+			if myModel.listModels == nil {
+				myModel.notice = "this provider picks its own model"
+				return myModel, nil
+			}
+			return myModel, loadModels(myModel.listModels)
+		case "ctrl+t", "alt+up", "alt+down":
+			myModel.handleThinkingKey(msg.String())
+			return myModel, nil
 		case "enter":
 			userInput := myModel.textarea.Value()
 			if userInput == "" {
 				return myModel, nil
 			}
-			myModel.userInputChan <- userInput
+			// A leading // sends a message starting with a slash that
+			// would otherwise run a command
+			if strings.HasPrefix(userInput, "//") {
+				userInput = userInput[1:]
+			} else if myModel.runCommand(userInput) {
+				myModel.textarea.Reset()
+				return myModel, nil
+			}
+			for _, mention := range myModel.loader.Mentions(userInput) {
+				if err := myModel.attach(mention); err != nil {
+					// Keep the input so the mention can be fixed
+					myModel.notice = err.Error()
+					return myModel, nil
+				}
+			}
 			newMsg := Message{
-				Role:    "You",
-				Content: userInput,
+				Role:        ai.RoleUser,
+				Content:     userInput,
+				Attachments: myModel.attachments,
+				CreatedAt:   time.Now(),
 			}
 			myModel.messages = append(myModel.messages, newMsg)
-			myModel.rebuildViewport()
+			myModel.attachments = nil
+			myModel.resize()
 			myModel.textarea.Reset()
-			myModel.viewport.GotoBottom()
-			myModel.waiting = true
-			myModel.textarea.Blur()
-			cmds = append(cmds, myModel.mySpinner.Tick, listenForOllamaOutput(myModel.ollamaOutputChan))
-			return myModel, tea.Batch(cmds...)
+			return myModel, myModel.send()
+		case "ctrl+r":
+			if !myModel.canRetry() {
+				return myModel, nil
+			}
+			// Drop the failed answer and ask again
+			last := len(myModel.messages) - 1
+			if myModel.messages[last].Role == ai.RoleAssistant {
+				myModel.messages = myModel.messages[:last]
+				delete(myModel.thinkingToggled, last)
+			}
+			return myModel, myModel.send()
 		}
 
 	case error:
 		myModel.err = msg
+		myModel.resize()
 
 	case cursor.BlinkMsg:
 		var cmd tea.Cmd
@@ -189,26 +387,35 @@ func (myModel *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 
 	case OutputMsg:
-		chunk := string(msg)
-		if chunk == "Thinking...\n" {
-			if !myModel.waiting {
-				myModel.waiting = true
-				myModel.textarea.Blur()
-				myModel.rebuildViewport()
-				return myModel, myModel.mySpinner.Tick
+		stopped := len(myModel.messages) > 0 && myModel.messages[len(myModel.messages)-1].Interrupted
+		switch msg.Type {
+		case ai.EventDone, ai.EventError:
+			if !stopped {
+				reply := myModel.currentReply()
+				reply.Usage = msg.Usage
+				reply.Sources = msg.Sources
+				if msg.Type == ai.EventError {
+					reply.Error = msg.Text
+				}
 			}
-			return myModel, nil
+			myModel.streaming = false
+			myModel.textarea.Focus()
+			myModel.rebuildViewport()
+			myModel.saveSession()
+			return myModel, textarea.Blink
 		}
-		myModel.waiting = false
-		myModel.textarea.Focus()
-		if len(myModel.messages) == 0 || myModel.messages[len(myModel.messages)-1].Role != "AI" {
-			newMsg := Message{
-				Role:    "AI",
-				Content: chunk,
-			}
-			myModel.messages = append(myModel.messages, newMsg)
-		} else {
-			myModel.messages[len(myModel.messages)-1].Content += chunk
+
+		if stopped {
+			// Late chunk of a reply the user already stopped
+			return myModel, listenForOllamaOutput(myModel.ollamaOutputChan)
+		}
+		switch msg.Type {
+		case ai.EventStart:
+			myModel.currentReply()
+		case ai.EventThinking:
+			myModel.currentReply().Thinking += msg.Text
+		case ai.EventAnswer:
+			myModel.currentReply().Content += msg.Text
 		}
 		myModel.rebuildViewport()
 		return myModel, listenForOllamaOutput(myModel.ollamaOutputChan)
@@ -216,13 +423,13 @@ func (myModel *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		myModel.mySpinner, cmd = myModel.mySpinner.Update(msg)
-		if myModel.waiting {
+		if myModel.streaming {
 			myModel.rebuildViewport()
 			cmds = append(cmds, cmd)
 		}
 	}
 
-	if myModel.waiting {
+	if myModel.streaming {
 		var cmd tea.Cmd
 		myModel.mySpinner, cmd = myModel.mySpinner.Update(msg)
 		myModel.rebuildViewport()
@@ -246,7 +453,7 @@ func (myModel *Model) View() string {
 	}
 
 	var textareaView string
-	if myModel.waiting {
+	if myModel.streaming {
 		textareaView = lipgloss.NewStyle().
 			BorderStyle(lipgloss.NormalBorder()).
 			BorderForeground(lipgloss.Color("240")).
@@ -255,48 +462,419 @@ func (myModel *Model) View() string {
 		textareaView = myModel.styles.InputStyle.Render(myModel.textarea.View())
 	}
 
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
+	mainView := myModel.viewport.View()
+	if myModel.picking {
+		mainView = myModel.picker.View()
+	}
+
+	views := []string{
 		lipgloss.NewStyle().
 			BorderStyle(lipgloss.RoundedBorder()).
 			BorderForeground(myModel.styles.BorderColor).
-			Width(myModel.width-2).
-			Render(myModel.viewport.View()),
-		textareaView,
-	)
+			Width(myModel.width - 2).
+			Render(mainView),
+	}
+	if myModel.err != nil {
+		views = append(views, myModel.formatError(myModel.err.Error()))
+	}
+	views = append(views, myModel.statusLine())
+	if len(myModel.attachments) > 0 {
+		views = append(views, myModel.formatChips(myModel.attachments))
+	}
+	views = append(views, textareaView)
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
 }
 
-func listenForOllamaOutput(outputChannel <-chan string) tea.Cmd {
+func (myModel *Model) statusLine() string {
+	var parts []string
+	if myModel.model != "" {
+		parts = append(parts, "model: "+myModel.model)
+	}
+	if myModel.profile.Name != "" {
+		parts = append(parts, "profile: "+myModel.profile.Name)
+	}
+	switch {
+	case myModel.picking:
+		parts = append(parts, "enter select", "/ filter", "esc cancel")
+	case myModel.streaming && myModel.messages[len(myModel.messages)-1].Interrupted:
+		parts = append(parts, "stopping...")
+	case myModel.streaming:
+		parts = append(parts, "esc stop")
+	case myModel.canRetry():
+		parts = append(parts, "ctrl+r retry")
+	case myModel.listModels != nil:
+		parts = append(parts, "ctrl+o switch model")
+	}
+	if !myModel.picking && myModel.selectedThinking() >= 0 {
+		parts = append(parts, "ctrl+t thinking")
+	}
+	if myModel.notice != "" {
+		parts = append(parts, myModel.notice)
+	}
+	return myModel.styles.StatusStyle.Render(strings.Join(parts, " • "))
+}
+
+// updatePicker routes key presses to the model switcher while it is open.
+func (myModel *Model) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if myModel.picker.FilterState() != list.Filtering {
+		switch msg.String() {
+		case "ctrl+c":
+			return myModel.quit()
+		case "esc":
+			myModel.picking = false
+			myModel.textarea.Focus()
+			return myModel, textarea.Blink
+		case "enter":
+			if selected, ok := myModel.picker.SelectedItem().(modelItem); ok {
+				// A picked model outlasts switching profiles
+				myModel.SetModel(selected.Name)
+			}
+			myModel.picking = false
+			myModel.textarea.Focus()
+			return myModel, textarea.Blink
+		}
+	}
+
+	var cmd tea.Cmd
+	myModel.picker, cmd = myModel.picker.Update(msg)
+	return myModel, cmd
+}
+
+func loadModels(lister ModelLister) tea.Cmd {
+	return func() tea.Msg {
+		options, err := lister()
+		return modelsLoadedMsg{options: options, err: err}
+	}
+}
+
+func listenForOllamaOutput(outputChannel <-chan ai.Event) tea.Cmd {
 	return func() tea.Msg {
-		llamaMessage, ok := <-outputChannel
-		if !ok {
-			return OutputMsg(llamaMessage)
+		return OutputMsg(<-outputChannel)
+	}
+}
+
+// send asks the backend to answer the conversation so far.
+func (myModel *Model) send() tea.Cmd {
+	myModel.selected = -1
+	myModel.userInputChan <- ai.ChatRequest{
+		Model:    myModel.model,
+		Messages: myModel.history(),
+		System:   myModel.profile.System,
+		Options:  myModel.profile.Options,
+	}
+	myModel.saveSession()
+	myModel.streaming = true
+	myModel.textarea.Blur()
+	myModel.rebuildViewport()
+	return tea.Batch(myModel.mySpinner.Tick, listenForOllamaOutput(myModel.ollamaOutputChan))
+}
+
+// canRetry reports whether the last message failed to get an answer.
+func (myModel *Model) canRetry() bool {
+	if myModel.streaming || len(myModel.messages) == 0 {
+		return false
+	}
+	last := myModel.messages[len(myModel.messages)-1]
+	return last.Role == ai.RoleUser || last.Error != ""
+}
+
+// currentReply returns the AI message being streamed, starting one if the
+// last message is the user's.
+func (myModel *Model) currentReply() *Message {
+	if len(myModel.messages) == 0 || myModel.messages[len(myModel.messages)-1].Role != ai.RoleAssistant {
+		myModel.messages = append(myModel.messages, Message{Role: ai.RoleAssistant, Model: myModel.model, CreatedAt: time.Now()})
+	}
+	return &myModel.messages[len(myModel.messages)-1]
+}
+
+// stopGeneration asks the backend to cancel the reply in flight, keeps
+// whatever was received so far marked as interrupted and hands the
+// input back to the user.
+func (myModel *Model) stopGeneration() {
+	select {
+	case myModel.stopChan <- struct{}{}:
+	default:
+	}
+
+	if len(myModel.messages) == 0 || myModel.messages[len(myModel.messages)-1].Role != ai.RoleAssistant {
+		myModel.messages = append(myModel.messages, Message{Role: ai.RoleAssistant, CreatedAt: time.Now()})
+	}
+	// Input comes back with the done event that ends the stopped reply
+	myModel.messages[len(myModel.messages)-1].Interrupted = true
+	myModel.rebuildViewport()
+	myModel.saveSession()
+}
+
+func (myModel *Model) quit() (tea.Model, tea.Cmd) {
+	myModel.saveSession()
+	myModel.quitting = true
+	return myModel, tea.Quit
+}
+
+// saveSession persists the conversation when a session store is set.
+// Empty conversations are never written.
+func (myModel *Model) saveSession() {
+	if myModel.store == nil || len(myModel.messages) == 0 {
+		return
+	}
+	myModel.session.Messages = myModel.messages
+	if myModel.model != "" {
+		myModel.session.Model = myModel.model
+	}
+	if err := myModel.store.Save(myModel.session); err != nil {
+		myModel.notice = err.Error()
+	}
+}
+
+// history converts the on-screen messages into the conversation sent to
+// the backend. Empty replies, such as ones stopped before any output,
+// carry no context and are skipped.
+func (chatModel *Model) history() []ai.Message {
+	history := make([]ai.Message, 0, len(chatModel.messages))
+	for _, msg := range chatModel.messages {
+		if msg.Content == "" {
+			continue
 		}
-		return OutputMsg(llamaMessage)
+		history = append(history, ai.Message{Role: msg.Role, Content: attach.Inline(msg.Content, msg.Attachments)})
 	}
+	return history
 }
 
-func (chatModel *Model) formatMessage(msg Message) string {
+func (chatModel *Model) formatMessage(index int, msg Message) string {
 	// For AI messages, render with glamour
-	if msg.Role == "AI" {
-		renderedMessage, _ := chatModel.renderer.Render(msg.Content)
+	if msg.Role == ai.RoleAssistant {
+		content := msg.Content
+		if len(msg.Sources) > 0 && !msg.Interrupted {
+			content += rag.Footer(msg.Sources)
+		}
+		renderedMessage, _ := chatModel.renderer.Render(content)
+		if msg.Thinking != "" {
+			renderedMessage = chatModel.formatThinking(index, msg) + renderedMessage
+		}
+		if msg.Interrupted {
+			renderedMessage += chatModel.styles.NoticeStyle.Render("[interrupted]") + "\n"
+		}
+		if msg.Error != "" {
+			renderedMessage += chatModel.formatError(msg.Error) + "\n"
+		}
+		if msg.Usage != nil && !msg.Interrupted {
+			renderedMessage += chatModel.styles.NoticeStyle.Render(formatUsage(*msg.Usage)) + "\n"
+		}
 		return fmt.Sprintf("%s%s\n",
-			chatModel.styles.PromptStyle.Render(msg.Role+":"),
+			chatModel.styles.PromptStyle.Render(session.RoleLabel(msg.Role)+":"),
 			chatModel.styles.ChatStyle.Render(renderedMessage))
 	}
 
 	// For user messages, keep the original formatting
+	content := msg.Content
+	if len(msg.Attachments) > 0 {
+		content += "\n" + chatModel.formatChips(msg.Attachments)
+	}
 	return fmt.Sprintf("%s%s\n",
-		chatModel.styles.PromptStyle.Render(msg.Role+":"),
-		chatModel.styles.ChatStyle.Render(msg.Content))
+		chatModel.styles.PromptStyle.Render(session.RoleLabel(msg.Role)+":"),
+		chatModel.styles.ChatStyle.Render(content))
+}
+
+// formatChips renders attached files as a row of chips.
+func (chatModel *Model) formatChips(files []attach.File) string {
+	chips := make([]string, len(files))
+	for i, file := range files {
+		chips[i] = chatModel.styles.ChipStyle.Render(fmt.Sprintf("📎 %s %s", file.Path, units.FormatSize(int64(len(file.Content)))))
+	}
+	return lipgloss.NewStyle().Width(chatModel.width - 2).Render(strings.Join(chips, ""))
+}
+
+// attach adds the files matching pattern to the next message, replacing
+// earlier versions of the same files.
+func (myModel *Model) attach(pattern string) error {
+	files, err := myModel.loader.Load(pattern)
+	if err != nil {
+		return err
+	}
+	// The limit holds for the whole message, not each pattern
+	if limit := myModel.loader.MaxTotalSize; limit > 0 {
+		loaded := make(map[string]bool)
+		var total int64
+		for _, file := range files {
+			loaded[file.Path] = true
+			total += int64(len(file.Content))
+		}
+		for _, file := range myModel.attachments {
+			if !loaded[file.Path] {
+				total += int64(len(file.Content))
+			}
+		}
+		if total > limit {
+			return fmt.Errorf("attachments exceed %s, detach some files first", units.FormatSize(limit))
+		}
+	}
+	for _, file := range files {
+		myModel.detach(file.Path)
+		myModel.attachments = append(myModel.attachments, file)
+	}
+	myModel.resize()
+	return nil
+}
+
+// detach removes path from the next message and reports whether it was
+// attached.
+func (myModel *Model) detach(path string) bool {
+	for i, file := range myModel.attachments {
+		if file.Path == path {
+			myModel.attachments = append(myModel.attachments[:i], myModel.attachments[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// resize fits the viewport between the border, the status line and the
+// input, whose height depends on the attachment chips and any error shown.
+func (myModel *Model) resize() {
+	headerHeight := 1
+	inputTextHeight := 6 // textarea height + margins
+	viewportHeight := myModel.height - headerHeight - inputTextHeight - 3
+	viewportHeight -= lipgloss.Height(myModel.statusLine())
+	if len(myModel.attachments) > 0 {
+		viewportHeight -= lipgloss.Height(myModel.formatChips(myModel.attachments))
+	}
+	if myModel.err != nil {
+		viewportHeight -= lipgloss.Height(myModel.formatError(myModel.err.Error()))
+	}
+
+	myModel.viewport.Width = myModel.width - 4
+	myModel.viewport.Height = max(viewportHeight, 1)
+	myModel.textarea.SetWidth(myModel.width - 2)
+	myModel.textarea.SetHeight(4)
+	if myModel.picking {
+		myModel.picker.SetSize(myModel.width-4, myModel.viewport.Height)
+	}
+	if len(myModel.messages) > 0 {
+		myModel.rebuildViewport()
+	}
+}
+
+// formatUsage summarises the timing and token counts of a reply.
+func formatUsage(usage ai.Usage) string {
+	parts := []string{
+		fmt.Sprintf("%.1fs to first token", usage.TimeToFirstToken.Seconds()),
+		fmt.Sprintf("%.1fs total", usage.Duration.Seconds()),
+	}
+	if speed := usage.TokensPerSecond(); speed > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f tok/s", speed))
+	}
+	if usage.PromptTokens > 0 || usage.CompletionTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d prompt / %d completion tokens", usage.PromptTokens, usage.CompletionTokens))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatError renders text as an error bubble that fits the viewport.
+func (chatModel *Model) formatError(text string) string {
+	width := chatModel.viewport.Width - 10
+	if width < 20 {
+		width = 20
+	}
+	return chatModel.styles.ErrorStyle.Width(width).Render("✗ " + text)
+}
+
+// formatThinking renders the reasoning of msg as a dimmed block. It is shown
+// while the model is still reasoning and collapsed to one line once the
+// answer starts, unless the user toggled it with ctrl+t.
+func (chatModel *Model) formatThinking(index int, msg Message) string {
+	expanded := chatModel.thinkingExpanded(index)
+
+	header := "▸ Thinking"
+	if expanded {
+		header = "▾ Thinking"
+	} else {
+		header += fmt.Sprintf(" (%d words)", len(strings.Fields(msg.Thinking)))
+	}
+	if index == chatModel.selectedThinking() && !chatModel.streaming {
+		header += " · ctrl+t"
+	}
+	if !expanded {
+		return chatModel.styles.ThinkingStyle.Render(header) + "\n"
+	}
+
+	width := chatModel.viewport.Width - 8
+	if width < 20 {
+		width = 20
+	}
+	body := lipgloss.NewStyle().Width(width).Render(strings.TrimSpace(msg.Thinking))
+	return chatModel.styles.ThinkingStyle.Render(header+"\n"+body) + "\n"
+}
+
+func (chatModel *Model) thinkingExpanded(index int) bool {
+	if toggled, ok := chatModel.thinkingToggled[index]; ok {
+		return toggled
+	}
+	return chatModel.messages[index].Content == "" && index == len(chatModel.messages)-1
+}
+
+// selectedThinking returns the index of the message ctrl+t toggles, or -1
+// if no answer has reasoning.
+func (chatModel *Model) selectedThinking() int {
+	if chatModel.selected >= 0 {
+		return chatModel.selected
+	}
+	for i := len(chatModel.messages) - 1; i >= 0; i-- {
+		if chatModel.messages[i].Thinking != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleThinkingKey toggles the reasoning of the selected answer on ctrl+t
+// and moves the selection between answers on alt+up and alt+down.
+func (myModel *Model) handleThinkingKey(key string) {
+	current := myModel.selectedThinking()
+	if current < 0 {
+		return
+	}
+
+	switch key {
+	case "ctrl+t":
+		myModel.thinkingToggled[current] = !myModel.thinkingExpanded(current)
+	case "alt+up":
+		for i := current - 1; i >= 0; i-- {
+			if myModel.messages[i].Thinking != "" {
+				myModel.selected = i
+				break
+			}
+		}
+	case "alt+down":
+		myModel.selected = -1
+		for i := current + 1; i < len(myModel.messages); i++ {
+			if myModel.messages[i].Thinking != "" {
+				myModel.selected = i
+				break
+			}
+		}
+	}
+
+	// Keep the scroll position so toggling an earlier answer does not jump
+	offset := myModel.viewport.YOffset
+	myModel.rebuildViewport()
+	if myModel.selected >= 0 {
+		myModel.viewport.SetYOffset(offset)
+	}
+}
+
+// awaitingAnswer reports whether no answer text of the current reply has
+// arrived yet.
+func (chatModel *Model) awaitingAnswer() bool {
+	last := chatModel.messages[len(chatModel.messages)-1]
+	return last.Role == ai.RoleUser || (last.Content == "" && !last.Interrupted)
 }
 
 func (chatModel *Model) rebuildViewport() {
 	var strBuilder strings.Builder
-	for _, msg := range chatModel.messages {
-		strBuilder.WriteString(chatModel.formatMessage(msg))
+	for i, msg := range chatModel.messages {
+		strBuilder.WriteString(chatModel.formatMessage(i, msg))
 	}
-	if chatModel.waiting {
+	if chatModel.streaming && chatModel.awaitingAnswer() {
 		strBuilder.WriteString(fmt.Sprintf("%s Thinking...", chatModel.mySpinner.View()))
 	}
 	chatModel.viewport.SetContent(strBuilder.String())
@@ -0,0 +1,201 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/attach"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// testChat is a chat model whose backend channels the test controls.
+type testChat struct {
+	model    *Model
+	requests chan ai.ChatRequest
+	stops    chan struct{}
+}
+
+func newTestChat(t *testing.T) *testChat {
+	t.Helper()
+	requests := make(chan ai.ChatRequest, 10)
+	stops := make(chan struct{}, 1)
+	chat := &testChat{model: New(requests, make(chan ai.Event), stops), requests: requests, stops: stops}
+	chat.update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	return chat
+}
+
+func (chat *testChat) update(msg tea.Msg) tea.Cmd {
+	_, cmd := chat.model.Update(msg)
+	return cmd
+}
+
+// send types text and presses enter.
+func (chat *testChat) send(text string) {
+	chat.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	chat.update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestViewFitsTerminal(t *testing.T) {
+	chat := newTestChat(t)
+	for _, height := range []int{20, 30, 45} {
+		chat.update(tea.WindowSizeMsg{Width: 80, Height: height})
+		if got := lipgloss.Height(chat.model.View()); got != height {
+			t.Errorf("View() is %d rows high in a %d row terminal", got, height)
+		}
+	}
+}
+
+func TestEscStopsStreamingReply(t *testing.T) {
+	chat := newTestChat(t)
+	chat.send("hello")
+	if len(chat.requests) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(chat.requests))
+	}
+
+	chat.update(OutputMsg{Type: ai.EventStart})
+	chat.update(OutputMsg{Type: ai.EventAnswer, Text: "Hel"})
+
+	cmd := chat.update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil || chat.model.Quitting() {
+		t.Fatal("esc during the reply quit the chat instead of stopping the reply")
+	}
+	select {
+	case <-chat.stops:
+	default:
+		t.Fatal("esc during the reply did not ask the backend to stop")
+	}
+	last := chat.model.messages[len(chat.model.messages)-1]
+	if last.Role != ai.RoleAssistant || last.Content != "Hel" || !last.Interrupted {
+		t.Errorf("last message = %+v, want the partial reply marked interrupted", last)
+	}
+
+	// The done event of the stopped reply hands the input back
+	chat.update(OutputMsg{Type: ai.EventDone})
+	if chat.model.streaming {
+		t.Error("still streaming after the done event")
+	}
+	chat.update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !chat.model.Quitting() {
+		t.Error("esc after the reply should quit")
+	}
+}
+
+func TestMentionOfNoFileIsSentAsText(t *testing.T) {
+	chat := newTestChat(t)
+	chat.send("what does @Override do? ping @team")
+	if len(chat.requests) != 1 {
+		t.Fatalf("sent %d requests, want 1 (notice %q)", len(chat.requests), chat.model.notice)
+	}
+	last := chat.model.messages[len(chat.model.messages)-1]
+	if last.Content != "what does @Override do? ping @team" || len(last.Attachments) != 0 {
+		t.Errorf("last message = %+v, want the text without attachments", last)
+	}
+}
+
+func TestAttachLimitsTheWholeMessage(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(strings.Repeat("x", 40)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chat := newTestChat(t)
+	chat.model.loader = attach.NewLoader(root)
+	chat.model.loader.MaxTotalSize = 100
+
+	for _, name := range []string{"a.txt", "b.txt", "b.txt"} {
+		if err := chat.model.attach(name); err != nil {
+			t.Fatalf("attach(%q) error = %v", name, err)
+		}
+	}
+	if err := chat.model.attach("c.txt"); err == nil || !strings.Contains(err.Error(), "attachments exceed") {
+		t.Errorf("attach() over the limit error = %v", err)
+	}
+	if len(chat.model.attachments) != 2 {
+		t.Errorf("attached %d files, want 2", len(chat.model.attachments))
+	}
+}
+
+func TestSlashInput(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantMessage string
+	}{
+		{input: "/help"},
+		{input: "/usr/local/bin is not on my PATH, why?", wantMessage: "/usr/local/bin is not on my PATH, why?"},
+		{input: "/helpme", wantMessage: "/helpme"},
+		{input: "//help", wantMessage: "/help"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			chat := newTestChat(t)
+			chat.send(tt.input)
+			if tt.wantMessage == "" {
+				if len(chat.requests) != 0 || !strings.Contains(chat.model.notice, "/attach") {
+					t.Errorf("%q sent %d requests, notice %q, want the command run", tt.input, len(chat.requests), chat.model.notice)
+				}
+				return
+			}
+			if len(chat.requests) != 1 {
+				t.Fatalf("%q sent %d requests, want 1 (notice %q)", tt.input, len(chat.requests), chat.model.notice)
+			}
+			if last := chat.model.messages[len(chat.model.messages)-1]; last.Content != tt.wantMessage {
+				t.Errorf("sent %q, want %q", last.Content, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestSourcesAreKeptOutOfTheAnswer(t *testing.T) {
+	chat := newTestChat(t)
+	chat.send("where are sessions saved?")
+	chat.update(OutputMsg{Type: ai.EventStart})
+	chat.update(OutputMsg{Type: ai.EventAnswer, Text: "In session.go."})
+	chat.update(OutputMsg{Type: ai.EventDone, Sources: []string{"pkg/session/session.go:80-110"}})
+
+	last := chat.model.messages[len(chat.model.messages)-1]
+	if last.Content != "In session.go." || len(last.Sources) != 1 {
+		t.Errorf("reply = %q with sources %v, want the sources apart", last.Content, last.Sources)
+	}
+	if history := chat.model.history(); strings.Contains(history[len(history)-1].Content, "Sources") {
+		t.Errorf("history sends the sources back: %q", history[len(history)-1].Content)
+	}
+	if !strings.Contains(chat.model.formatMessage(len(chat.model.messages)-1, last), "Sources") {
+		t.Error("the sources are not shown")
+	}
+}
+
+func TestProfileRestoresTheBaseModel(t *testing.T) {
+	chat := newTestChat(t)
+	chat.model.SetModel("qwq")
+	chat.model.SetProfiles([]Profile{{Name: "coder", Model: "qwen2.5-coder"}, {Name: "blunt", System: "Be blunt."}}, "")
+
+	steps := []struct {
+		input     string
+		wantModel string
+	}{
+		{input: "/profile coder", wantModel: "qwen2.5-coder"},
+		{input: "/profile blunt", wantModel: "qwq"},
+		{input: "/profile coder", wantModel: "qwen2.5-coder"},
+		{input: "/profile none", wantModel: "qwq"},
+	}
+	for _, step := range steps {
+		chat.send(step.input)
+		if chat.model.model != step.wantModel {
+			t.Errorf("after %s the model is %q, want %q", step.input, chat.model.model, step.wantModel)
+		}
+	}
+
+	// A chat started with the model of a profile goes back to its base
+	chat.model.SetModel("qwen2.5-coder")
+	chat.model.SetBaseModel("llama3.2")
+	chat.send("/profile none")
+	if chat.model.model != "llama3.2" {
+		t.Errorf("after /profile none the model is %q, want llama3.2", chat.model.model)
+	}
+}
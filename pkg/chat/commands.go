@@ -0,0 +1,210 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/andreivisan/quantum_cli/pkg/session"
+)
+
+// chatCommand is a slash command typed into the chat input.
+type chatCommand struct {
+	usage string
+	run   func(myModel *Model, args []string) error
+}
+
+var chatCommands map[string]chatCommand
+
+func init() {
+	chatCommands = map[string]chatCommand{
+		"/attach": {
+			usage: "/attach <path|glob|dir>...",
+			run:   attachCommand,
+		},
+		"/detach": {
+			usage: "/detach [path]...",
+			run:   detachCommand,
+		},
+		"/export": {
+			usage: "/export [md|json|html] [path] [--thinking]",
+			run:   exportCommand,
+		},
+		"/memory": {
+			usage: "/memory [on|off]",
+			run:   memoryCommand,
+		},
+		"/profile": {
+			usage: "/profile [name|none]",
+			run:   profileCommand,
+		},
+		"/help": {
+			usage: "/help",
+			run:   helpCommand,
+		},
+	}
+}
+
+// runCommand executes the slash command input starts with, reporting the
+// outcome in the status line. Input whose first word is not a command,
+// such as a path, is left alone and false is returned.
+func (myModel *Model) runCommand(input string) bool {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return false
+	}
+	command, ok := chatCommands[fields[0]]
+	if !ok {
+		return false
+	}
+	if err := command.run(myModel, fields[1:]); err != nil {
+		myModel.notice = fmt.Sprintf("%s: %v", fields[0], err)
+	}
+	return true
+}
+
+func helpCommand(myModel *Model, args []string) error {
+	usages := make([]string, 0, len(chatCommands))
+	for _, command := range chatCommands {
+		usages = append(usages, command.usage)
+	}
+	sort.Strings(usages)
+	usages = append(usages, "//text sends /text")
+	myModel.notice = strings.Join(usages, " • ")
+	return nil
+}
+
+func attachCommand(myModel *Model, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: /attach <path|glob|dir>...")
+	}
+	before := len(myModel.attachments)
+	for _, pattern := range args {
+		if err := myModel.attach(pattern); err != nil {
+			return err
+		}
+	}
+	myModel.notice = fmt.Sprintf("%d files attached to the next message", len(myModel.attachments))
+	if len(myModel.attachments) == before {
+		myModel.notice = "attachments refreshed"
+	}
+	return nil
+}
+
+// detachCommand removes the given files from the next message, or all of
+// them without arguments.
+func detachCommand(myModel *Model, args []string) error {
+	if len(args) == 0 {
+		myModel.attachments = nil
+	}
+	for _, path := range args {
+		if !myModel.detach(path) {
+			return fmt.Errorf("%s is not attached", path)
+		}
+	}
+	myModel.resize()
+	myModel.notice = fmt.Sprintf("%d files attached to the next message", len(myModel.attachments))
+	return nil
+}
+
+// memoryCommand switches recalling earlier sessions on or off, or shows
+// whether it is on without arguments.
+func memoryCommand(myModel *Model, args []string) error {
+	if myModel.memory == nil {
+		return errors.New("not available in this chat")
+	}
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && args[0] == "on":
+		myModel.memory.SetEnabled(true)
+	case len(args) == 1 && args[0] == "off":
+		myModel.memory.SetEnabled(false)
+	default:
+		return errors.New("usage: /memory [on|off]")
+	}
+	myModel.notice = "memory of earlier sessions is off"
+	if myModel.memory.Enabled() {
+		myModel.notice = "memory of earlier sessions is on"
+	}
+	return nil
+}
+
+// profileCommand switches to the named profile for the following
+// messages, or lists the profiles without arguments.
+func profileCommand(myModel *Model, args []string) error {
+	if len(myModel.profiles) == 0 {
+		return errors.New("no profiles, create one with 'qcli profiles create'")
+	}
+	if len(args) == 0 {
+		names := make([]string, len(myModel.profiles))
+		for i, profile := range myModel.profiles {
+			names[i] = profile.Name
+		}
+		myModel.notice = "profiles: " + strings.Join(names, ", ")
+		return nil
+	}
+	if len(args) > 1 {
+		return errors.New("usage: /profile [name|none]")
+	}
+	if args[0] == "none" {
+		myModel.profile = Profile{}
+		myModel.model = myModel.baseModel
+		myModel.notice = "profile cleared"
+		return nil
+	}
+	for _, profile := range myModel.profiles {
+		if profile.Name != args[0] {
+			continue
+		}
+		myModel.profile = profile
+		myModel.model = myModel.baseModel
+		// Backends that pick their own model have no model to replace
+		if profile.Model != "" && myModel.model != "" {
+			myModel.model = profile.Model
+		}
+		myModel.notice = "switched to profile " + profile.Name
+		return nil
+	}
+	return fmt.Errorf("unknown profile %s", args[0])
+}
+
+func exportCommand(myModel *Model, args []string) error {
+	options := session.ExportOptions{Format: session.FormatMarkdown}
+	var path string
+	for _, arg := range args {
+		if arg == "--thinking" {
+			options.IncludeThinking = true
+			continue
+		}
+		if format, err := session.ParseFormat(arg); err == nil && path == "" && !strings.Contains(arg, ".") {
+			options.Format = format
+			continue
+		}
+		path = arg
+	}
+
+	exported := myModel.session
+	if exported == nil {
+		exported = session.New(myModel.model)
+	}
+	exported.Messages = myModel.messages
+	if path == "" {
+		path = exported.ID + "." + string(options.Format)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := session.Export(file, exported, options); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	myModel.notice = "exported to " + path
+	return nil
+}
@@ -0,0 +1,45 @@
+package chat
+
+import (
+	"github.com/andreivisan/quantum_cli/pkg/menu"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// ModelOption is an installed model offered by the model switcher.
+type ModelOption struct {
+	Name    string
+	Details string
+}
+
+// ModelLister returns the models the user can switch to.
+type ModelLister func() ([]ModelOption, error)
+
+type modelsLoadedMsg struct {
+	options []ModelOption
+	err     error
+}
+
+type modelItem ModelOption
+
+func (option modelItem) Title() string       { return option.Name }
+func (option modelItem) Description() string { return option.Details }
+func (option modelItem) FilterValue() string { return option.Name }
+
+// newModelPicker builds the filterable model list, preselecting the
+// active model.
+func newModelPicker(options []ModelOption, active string, width int, height int) list.Model {
+	items := make([]list.Item, len(options))
+	selected := 0
+	for i, option := range options {
+		items[i] = modelItem(option)
+		if option.Name == active {
+			selected = i
+		}
+	}
+
+	picker := list.New(items, menu.NewDelegate(), width, height)
+	picker.Title = "Switch model"
+	picker.SetShowStatusBar(false)
+	picker.Select(selected)
+	return picker
+}
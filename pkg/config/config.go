@@ -0,0 +1,285 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the user's qcli settings. Values are resolved with the
+// precedence flags > QCLI_* environment variables > config file > defaults.
+type Config struct {
+	Provider      string `yaml:"provider"`
+	ServerURL     string `yaml:"server"`
+	OllamaURL     string `yaml:"ollama_url"`
+	Model         string `yaml:"model"`
+	Theme         string `yaml:"theme"`
+	HistoryBudget int    `yaml:"history_budget"`
+	// ConnectTimeout, IdleTimeout and Retries tune the connection to the
+	// AI backend, see ai.Transport.
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`
+	Retries        int           `yaml:"retries"`
+	// EmbedModel is the Ollama model that embeds indexed files and
+	// questions for --rag.
+	EmbedModel string `yaml:"embed_model"`
+	// VisionModel is the Ollama model that reads images for qcli ocr.
+	VisionModel string `yaml:"vision_model"`
+	// Memory recalls relevant exchanges of earlier sessions in the chat.
+	Memory bool `yaml:"memory"`
+	// Profile names the entry of Profiles used unless --profile picks
+	// another, "" for none.
+	Profile  string             `yaml:"profile"`
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+// setting describes one user-visible configuration key.
+type setting struct {
+	env string
+	get func(config *Config) string
+	set func(config *Config, value string) error
+}
+
+var settings = map[string]setting{
+	"profile": {
+		env: "QCLI_PROFILE",
+		get: func(config *Config) string { return config.Profile },
+		set: func(config *Config, value string) error { config.Profile = value; return nil },
+	},
+	"provider": {
+		env: "QCLI_PROVIDER",
+		get: func(config *Config) string { return config.Provider },
+		set: func(config *Config, value string) error {
+			switch value {
+			case "quantum", "ollama", "openai":
+				config.Provider = value
+				return nil
+			default:
+				return fmt.Errorf("invalid provider %q, expected quantum, ollama or openai", value)
+			}
+		},
+	},
+	"server": {
+		env: "QCLI_SERVER",
+		get: func(config *Config) string { return config.ServerURL },
+		set: func(config *Config, value string) error { config.ServerURL = value; return nil },
+	},
+	"embed_model": {
+		env: "QCLI_EMBED_MODEL",
+		get: func(config *Config) string { return config.EmbedModel },
+		set: func(config *Config, value string) error { config.EmbedModel = value; return nil },
+	},
+	"vision_model": {
+		env: "QCLI_VISION_MODEL",
+		get: func(config *Config) string { return config.VisionModel },
+		set: func(config *Config, value string) error { config.VisionModel = value; return nil },
+	},
+	"ollama_url": {
+		env: "QCLI_OLLAMA_URL",
+		get: func(config *Config) string { return config.OllamaURL },
+		set: func(config *Config, value string) error { config.OllamaURL = value; return nil },
+	},
+	"memory": {
+		env: "QCLI_MEMORY",
+		get: func(config *Config) string { return strconv.FormatBool(config.Memory) },
+		set: func(config *Config, value string) error {
+			memory, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid memory %q, expected true or false", value)
+			}
+			config.Memory = memory
+			return nil
+		},
+	},
+	"model": {
+		env: "QCLI_MODEL",
+		get: func(config *Config) string { return config.Model },
+		set: func(config *Config, value string) error { config.Model = value; return nil },
+	},
+	"theme": {
+		env: "QCLI_THEME",
+		get: func(config *Config) string { return config.Theme },
+		set: func(config *Config, value string) error { config.Theme = value; return nil },
+	},
+	"history_budget": {
+		env: "QCLI_HISTORY_BUDGET",
+		get: func(config *Config) string { return strconv.Itoa(config.HistoryBudget) },
+		set: func(config *Config, value string) error {
+			budget, err := strconv.Atoi(value)
+			if err != nil || budget < 0 {
+				return fmt.Errorf("invalid history_budget %q, expected a non-negative number", value)
+			}
+			config.HistoryBudget = budget
+			return nil
+		},
+	},
+	"connect_timeout": {
+		env: "QCLI_CONNECT_TIMEOUT",
+		get: func(config *Config) string { return config.ConnectTimeout.String() },
+		set: func(config *Config, value string) error {
+			return setDuration(&config.ConnectTimeout, "connect_timeout", value)
+		},
+	},
+	"idle_timeout": {
+		env: "QCLI_IDLE_TIMEOUT",
+		get: func(config *Config) string { return config.IdleTimeout.String() },
+		set: func(config *Config, value string) error {
+			return setDuration(&config.IdleTimeout, "idle_timeout", value)
+		},
+	},
+	"retries": {
+		env: "QCLI_RETRIES",
+		get: func(config *Config) string { return strconv.Itoa(config.Retries) },
+		set: func(config *Config, value string) error {
+			retries, err := strconv.Atoi(value)
+			if err != nil || retries < 0 {
+				return fmt.Errorf("invalid retries %q, expected a non-negative number", value)
+			}
+			config.Retries = retries
+			return nil
+		},
+	},
+}
+
+// setDuration parses value such as "30s" into target. Zero disables the
+// timeout.
+func setDuration(target *time.Duration, key string, value string) error {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return fmt.Errorf("invalid %s %q, expected a duration such as 30s", key, value)
+	}
+	*target = duration
+	return nil
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() *Config {
+	return &Config{
+		Provider:       "quantum",
+		ServerURL:      "",
+		OllamaURL:      "http://localhost:11434",
+		Model:          "qwq",
+		Theme:          "auto",
+		HistoryBudget:  24000,
+		ConnectTimeout: 10 * time.Second,
+		IdleTimeout:    2 * time.Minute,
+		Retries:        3,
+		EmbedModel:     "nomic-embed-text",
+		VisionModel:    "llama3.2-vision",
+	}
+}
+
+// Path returns the location of the config file, honouring
+// $XDG_CONFIG_HOME and falling back to ~/.config.
+func Path() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %v", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "qcli", "config.yaml"), nil
+}
+
+// DataDir returns the directory of what qcli saves, such as sessions and
+// indexes, honouring $XDG_DATA_HOME and falling back to ~/.local/share.
+func DataDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %v", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "qcli"), nil
+}
+
+// Load reads the config file at path on top of the defaults. A missing
+// file is not an error.
+func Load(path string) (*Config, error) {
+	config := Default()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// Save writes the config to path, creating its directory if needed.
+func (config *Config) Save(path string) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+	return nil
+}
+
+// ApplyEnv overrides settings with the QCLI_* variables found by lookup,
+// which is usually os.LookupEnv.
+func (config *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, key := range Keys() {
+		value, ok := lookup(settings[key].env)
+		if !ok {
+			continue
+		}
+		if err := settings[key].set(config, value); err != nil {
+			return fmt.Errorf("%s: %v", settings[key].env, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the value of key as a string.
+func (config *Config) Get(key string) (string, error) {
+	setting, ok := settings[key]
+	if !ok {
+		return "", fmt.Errorf("unknown config key %q", key)
+	}
+	return setting.get(config), nil
+}
+
+// Set parses and stores value under key.
+func (config *Config) Set(key string, value string) error {
+	setting, ok := settings[key]
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	return setting.set(config, value)
+}
+
+// Keys returns every config key in a stable order.
+func Keys() []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EnvVar returns the environment variable that overrides key.
+func EnvVar(key string) string {
+	return settings[key].env
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	got, err := Path()
+	if err != nil {
+		t.Fatalf("Path() unexpected error: %v", err)
+	}
+	if want := filepath.Join("/tmp/xdg", "qcli", "config.yaml"); got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/tmp/xdg")
+
+	got, err := DataDir()
+	if err != nil {
+		t.Fatalf("DataDir() unexpected error: %v", err)
+	}
+	if want := filepath.Join("/tmp/xdg", "qcli"); got != want {
+		t.Errorf("DataDir() = %q, want %q", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing file uses defaults", func(t *testing.T) {
+		config, err := Load(filepath.Join(dir, "missing.yaml"))
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(config, Default()) {
+			t.Errorf("Load() = %+v, want defaults", config)
+		}
+	})
+
+	t.Run("file overrides defaults", func(t *testing.T) {
+		path := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(path, []byte("model: llama3.2\nprovider: ollama\nidle_timeout: 30s\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		config, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if config.Model != "llama3.2" || config.Provider != "ollama" || config.IdleTimeout != 30*time.Second {
+			t.Errorf("Load() = %+v, want file values", config)
+		}
+		if config.OllamaURL != Default().OllamaURL {
+			t.Errorf("Load() OllamaURL = %q, want default", config.OllamaURL)
+		}
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		path := filepath.Join(dir, "broken.yaml")
+		if err := os.WriteFile(path, []byte("model: [\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Error("Load() expected an error for invalid yaml")
+		}
+	})
+}
+
+func TestConfig_SaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qcli", "config.yaml")
+	config := Default()
+	if err := config.Set("theme", "dracula"); err != nil {
+		t.Fatal(err)
+	}
+	reviewer := Profile{SystemPrompt: "Be blunt.", NumCtx: 8192}
+	if err := reviewer.Set("temperature", "0"); err != nil {
+		t.Fatal(err)
+	}
+	config.Profiles = map[string]Profile{"reviewer": reviewer}
+	if err := config.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, config) {
+		t.Errorf("Load() = %+v, want %+v", loaded, config)
+	}
+}
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"QCLI_MODEL":          "qwen2.5-coder",
+		"QCLI_HISTORY_BUDGET": "1000",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	config := Default()
+	if err := config.ApplyEnv(lookup); err != nil {
+		t.Fatalf("ApplyEnv() unexpected error: %v", err)
+	}
+	if config.Model != "qwen2.5-coder" || config.HistoryBudget != 1000 {
+		t.Errorf("ApplyEnv() = %+v, want env values", config)
+	}
+
+	env["QCLI_PROVIDER"] = "bard"
+	if err := config.ApplyEnv(lookup); err == nil {
+		t.Error("ApplyEnv() expected an error for an invalid provider")
+	}
+}
+
+func TestConfig_GetSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "model", key: "model", value: "qwq"},
+		{name: "history budget", key: "history_budget", value: "500"},
+		{name: "negative history budget", key: "history_budget", value: "-1", wantErr: true},
+		{name: "idle timeout", key: "idle_timeout", value: "1m30s"},
+		{name: "invalid idle timeout", key: "idle_timeout", value: "soon", wantErr: true},
+		{name: "retries", key: "retries", value: "0"},
+		{name: "embed model", key: "embed_model", value: "mxbai-embed-large"},
+		{name: "vision model", key: "vision_model", value: "llava"},
+		{name: "memory", key: "memory", value: "true"},
+		{name: "invalid memory", key: "memory", value: "sometimes", wantErr: true},
+		{name: "unknown key", key: "colour", value: "red", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Default()
+			err := config.Set(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got, _ := config.Get(tt.key); got != tt.value {
+				t.Errorf("Get() = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
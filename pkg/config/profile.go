@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Profile is a named persona: a system prompt and the model and sampling
+// options to answer with. Empty fields keep the backend's defaults.
+type Profile struct {
+	SystemPrompt string   `yaml:"system_prompt,omitempty"`
+	Model        string   `yaml:"model,omitempty"`
+	Temperature  *float64 `yaml:"temperature,omitempty"`
+	TopP         *float64 `yaml:"top_p,omitempty"`
+	NumCtx       int      `yaml:"num_ctx,omitempty"`
+}
+
+// profileSetting describes one key of a profile. Setting "" clears it.
+type profileSetting struct {
+	get func(profile *Profile) string
+	set func(profile *Profile, value string) error
+}
+
+var profileSettings = map[string]profileSetting{
+	"system_prompt": {
+		get: func(profile *Profile) string { return profile.SystemPrompt },
+		set: func(profile *Profile, value string) error { profile.SystemPrompt = value; return nil },
+	},
+	"model": {
+		get: func(profile *Profile) string { return profile.Model },
+		set: func(profile *Profile, value string) error { profile.Model = value; return nil },
+	},
+	"temperature": {
+		get: func(profile *Profile) string { return formatFloat(profile.Temperature) },
+		set: func(profile *Profile, value string) error {
+			return setFloat(&profile.Temperature, "temperature", value, 2)
+		},
+	},
+	"top_p": {
+		get: func(profile *Profile) string { return formatFloat(profile.TopP) },
+		set: func(profile *Profile, value string) error {
+			return setFloat(&profile.TopP, "top_p", value, 1)
+		},
+	},
+	"num_ctx": {
+		get: func(profile *Profile) string {
+			if profile.NumCtx == 0 {
+				return ""
+			}
+			return strconv.Itoa(profile.NumCtx)
+		},
+		set: func(profile *Profile, value string) error {
+			if value == "" {
+				profile.NumCtx = 0
+				return nil
+			}
+			numCtx, err := strconv.Atoi(value)
+			if err != nil || numCtx < 0 {
+				return fmt.Errorf("invalid num_ctx %q, expected a number of tokens", value)
+			}
+			profile.NumCtx = numCtx
+			return nil
+		},
+	},
+}
+
+// setFloat parses value into target, which must lie between 0 and max.
+func setFloat(target **float64, key string, value string, max float64) error {
+	if value == "" {
+		*target = nil
+		return nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 || number > max {
+		return fmt.Errorf("invalid %s %q, expected a number from 0 to %g", key, value, max)
+	}
+	*target = &number
+	return nil
+}
+
+func formatFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// Get returns the value of key as a string, "" when unset.
+func (profile *Profile) Get(key string) (string, error) {
+	setting, ok := profileSettings[key]
+	if !ok {
+		return "", fmt.Errorf("unknown profile key %q, expected one of %s", key, strings.Join(ProfileKeys(), ", "))
+	}
+	return setting.get(profile), nil
+}
+
+// Set parses and stores value under key. An empty value clears it.
+func (profile *Profile) Set(key string, value string) error {
+	setting, ok := profileSettings[key]
+	if !ok {
+		return fmt.Errorf("unknown profile key %q, expected one of %s", key, strings.Join(ProfileKeys(), ", "))
+	}
+	return setting.set(profile, value)
+}
+
+// ProfileKeys returns every profile key in a stable order.
+func ProfileKeys() []string {
+	keys := make([]string, 0, len(profileSettings))
+	for key := range profileSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate checks the ranges of the sampling options, for profiles that
+// were not built with Set.
+func (profile *Profile) Validate() error {
+	for _, key := range []string{"temperature", "top_p", "num_ctx"} {
+		value, _ := profile.Get(key)
+		if err := (&Profile{}).Set(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LookupProfile returns the profile called name.
+func (config *Config) LookupProfile(name string) (Profile, error) {
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, see 'qcli profiles list'", name)
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the names of the profiles in lexical order.
+func (config *Config) ProfileNames() []string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateProfileName rejects names that cannot be typed after /profile,
+// where "none" clears the profile.
+func ValidateProfileName(name string) error {
+	valid := name != "" && name != "none"
+	for _, r := range name {
+		valid = valid && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_')
+	}
+	if !valid {
+		return fmt.Errorf("invalid profile name %q, use letters, digits, - or _", name)
+	}
+	return nil
+}
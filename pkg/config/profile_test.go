@@ -0,0 +1,76 @@
+package config
+
+import "testing"
+
+func TestProfile_GetSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "system prompt", key: "system_prompt", value: "You review Go code."},
+		{name: "model", key: "model", value: "llama3.2"},
+		{name: "zero temperature", key: "temperature", value: "0"},
+		{name: "temperature", key: "temperature", value: "1.5"},
+		{name: "temperature too high", key: "temperature", value: "2.5", wantErr: true},
+		{name: "top p", key: "top_p", value: "0.9"},
+		{name: "top p too high", key: "top_p", value: "1.1", wantErr: true},
+		{name: "num ctx", key: "num_ctx", value: "16384"},
+		{name: "negative num ctx", key: "num_ctx", value: "-1", wantErr: true},
+		{name: "cleared", key: "temperature", value: ""},
+		{name: "unknown key", key: "seed", value: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := Profile{}
+			err := profile.Set(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got, _ := profile.Get(tt.key); got != tt.value {
+				t.Errorf("Get() = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestProfile_Validate(t *testing.T) {
+	temperature := 3.0
+	if err := (&Profile{Temperature: &temperature}).Validate(); err == nil {
+		t.Error("Validate() expected an error for temperature 3")
+	}
+	temperature = 0.7
+	if err := (&Profile{Temperature: &temperature, NumCtx: 4096}).Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"reviewer": true, "go-expert_2": true, "": false, "none": false, "two words": false, "a/b": false,
+	} {
+		if err := ValidateProfileName(name); (err == nil) != valid {
+			t.Errorf("ValidateProfileName(%q) error = %v, want valid %v", name, err, valid)
+		}
+	}
+}
+
+func TestConfig_LookupProfile(t *testing.T) {
+	config := Default()
+	config.Profiles = map[string]Profile{"writer": {}, "reviewer": {Model: "qwq"}}
+
+	if profile, err := config.LookupProfile("reviewer"); err != nil || profile.Model != "qwq" {
+		t.Errorf("LookupProfile() = %+v, %v, want the reviewer", profile, err)
+	}
+	if _, err := config.LookupProfile("poet"); err == nil {
+		t.Error("LookupProfile() expected an error for an unknown profile")
+	}
+	if names := config.ProfileNames(); len(names) != 2 || names[0] != "reviewer" {
+		t.Errorf("ProfileNames() = %v, want them sorted", names)
+	}
+}
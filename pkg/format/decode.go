@@ -0,0 +1,155 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// decode parses data into a YAML node, which keeps the order of keys for
+// every format but TOML.
+func decode(data []byte, from Format) (*yaml.Node, error) {
+	switch from {
+	case JSON:
+		return decodeJSON(data)
+	case YAML:
+		return decodeYAML(data)
+	case TOML:
+		return decodeTOML(data)
+	case XML:
+		return decodeXML(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q", from)
+	}
+}
+
+func decodeJSON(data []byte) (*yaml.Node, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := jsonValue(decoder)
+	if err == nil {
+		if _, extra := decoder.Token(); extra != io.EOF {
+			err = errors.New("unexpected data after the top-level value")
+		}
+	}
+	if err == nil {
+		return node, nil
+	}
+
+	offset := decoder.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// The offset is just past the offending byte
+		offset = syntaxErr.Offset - 1
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || syntaxErr != nil && syntaxErr.Error() == "unexpected end of JSON input" {
+		offset = int64(len(bytes.TrimRight(data, " \t\r\n")))
+		err = errors.New("unexpected end of input")
+	}
+	line, column := position(data, offset)
+	return nil, &SyntaxError{Format: JSON, Line: line, Column: column, Message: err.Error()}
+}
+
+// jsonValue reads the next value of decoder, keeping the order of object
+// keys and the text of numbers.
+func jsonValue(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if token == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for decoder.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, scalar("!!str", key.(string)))
+			}
+			value, err := jsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		// The closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return scalar("!!str", token), nil
+	case json.Number:
+		if strings.ContainsAny(token.String(), ".eE") {
+			return scalar("!!float", token.String()), nil
+		}
+		return scalar("!!int", token.String()), nil
+	case bool:
+		return scalar("!!bool", strconv.FormatBool(token)), nil
+	default:
+		return scalar("!!null", "null"), nil
+	}
+}
+
+func scalar(tag string, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+var yamlLine = regexp.MustCompile(`^yaml: (?:unmarshal errors:\n\s*)?line (\d+): `)
+
+func decodeYAML(data []byte) (*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var document yaml.Node
+	err := decoder.Decode(&document)
+	if errors.Is(err, io.EOF) || err == nil && len(document.Content) == 0 {
+		return nil, &SyntaxError{Format: YAML, Message: "empty document"}
+	}
+	if err == nil {
+		// Every other format holds one value, so a stream of documents
+		// cannot be converted without dropping all but the first
+		var next yaml.Node
+		if err = decoder.Decode(&next); errors.Is(err, io.EOF) {
+			return document.Content[0], nil
+		}
+		if err == nil {
+			return nil, &SyntaxError{Format: YAML, Line: next.Line, Message: "only one document is supported, split the input at ---"}
+		}
+	}
+	syntaxErr := &SyntaxError{Format: YAML, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	if match := yamlLine.FindStringSubmatch(err.Error()); match != nil {
+		syntaxErr.Line, _ = strconv.Atoi(match[1])
+		syntaxErr.Message = err.Error()[len(match[0]):]
+	}
+	return nil, syntaxErr
+}
+
+func decodeTOML(data []byte) (*yaml.Node, error) {
+	var value map[string]any
+	if err := toml.Unmarshal(data, &value); err != nil {
+		syntaxErr := &SyntaxError{Format: TOML, Message: err.Error()}
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			syntaxErr.Line, syntaxErr.Column = decodeErr.Position()
+			syntaxErr.Message = strings.TrimPrefix(decodeErr.Error(), "toml: ")
+		}
+		return nil, syntaxErr
+	}
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
@@ -0,0 +1,179 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// encode writes node in the to format.
+func encode(node *yaml.Node, to Format, indent string, minify bool) ([]byte, error) {
+	switch to {
+	case JSON:
+		var buffer bytes.Buffer
+		if err := writeJSON(&buffer, node); err != nil {
+			return nil, err
+		}
+		return reformatJSON(buffer.Bytes(), indent, minify)
+	case YAML:
+		var buffer bytes.Buffer
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(len(indent))
+		if err := encoder.Encode(node); err != nil {
+			return nil, fmt.Errorf("failed to write yaml: %v", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write yaml: %v", err)
+		}
+		return buffer.Bytes(), nil
+	case TOML:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to read the document: %v", err)
+		}
+		if _, ok := value.(map[string]any); !ok {
+			return nil, fmt.Errorf("toml needs a table at the top level, not a %s", kindName(node))
+		}
+		if path, ok := findNull(node, ""); ok {
+			return nil, fmt.Errorf("toml cannot represent null at %s", path)
+		}
+		var buffer bytes.Buffer
+		encoder := toml.NewEncoder(&buffer).SetIndentSymbol(indent)
+		if err := encoder.Encode(value); err != nil {
+			return nil, fmt.Errorf("failed to write toml: %v", err)
+		}
+		return buffer.Bytes(), nil
+	case XML:
+		return encodeXML(node, indent, minify)
+	default:
+		return nil, fmt.Errorf("unknown format %q", to)
+	}
+}
+
+// reformatJSON indents or compacts valid JSON, ending it with a newline.
+func reformatJSON(data []byte, indent string, minify bool) ([]byte, error) {
+	var buffer bytes.Buffer
+	var err error
+	if minify {
+		err = json.Compact(&buffer, data)
+	} else {
+		err = json.Indent(&buffer, data, "", indent)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write json: %v", err)
+	}
+	buffer.WriteByte('\n')
+	return buffer.Bytes(), nil
+}
+
+// writeJSON writes node as compact JSON in the order of its keys.
+func writeJSON(buffer *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return writeJSON(buffer, node.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buffer, node.Alias)
+	case yaml.MappingNode:
+		buffer.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return fmt.Errorf("json keys must be strings, not a %s at line %d", kindName(key), key.Line)
+			}
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			encoded, _ := json.Marshal(key.Value)
+			buffer.Write(encoded)
+			buffer.WriteByte(':')
+			if err := writeJSON(buffer, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+		return nil
+	case yaml.SequenceNode:
+		buffer.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeJSON(buffer, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+		return nil
+	}
+
+	var value any = node.Value
+	switch node.ShortTag() {
+	case "!!int", "!!float":
+		// Numbers already written as JSON keep their precision
+		if json.Valid([]byte(node.Value)) {
+			buffer.WriteString(node.Value)
+			return nil
+		}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+	case "!!null", "!!bool":
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+	}
+	if number, ok := value.(float64); ok && (math.IsInf(number, 0) || math.IsNaN(number)) {
+		return fmt.Errorf("json cannot represent %s at line %d", node.Value, node.Line)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buffer.Write(encoded)
+	return nil
+}
+
+// findNull returns the path of the first null in node, which TOML has no
+// way to write.
+func findNull(node *yaml.Node, path string) (string, bool) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return findNull(node.Content[0], path)
+	case yaml.AliasNode:
+		return findNull(node.Alias, path)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+			if found, ok := findNull(node.Content[i+1], key); ok {
+				return found, true
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if found, ok := findNull(item, fmt.Sprintf("%s[%d]", path, i)); ok {
+				return found, true
+			}
+		}
+	case yaml.ScalarNode:
+		return path, node.ShortTag() == "!!null"
+	}
+	return "", false
+}
+
+func kindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	default:
+		return "value"
+	}
+}
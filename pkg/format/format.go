@@ -0,0 +1,166 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a structured data format qcli fmt reads and writes.
+type Format string
+
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+	TOML Format = "toml"
+	XML  Format = "xml"
+)
+
+// Formats lists every format in the order the formatter cycles through
+// them.
+var Formats = []Format{JSON, YAML, TOML, XML}
+
+// DefaultIndent is the indentation width used when Options leave it unset.
+const DefaultIndent = 2
+
+// ParseFormat accepts json, yaml, yml, toml and xml.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSON, nil
+	case "yaml", "yml":
+		return YAML, nil
+	case "toml":
+		return TOML, nil
+	case "xml":
+		return XML, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected json, yaml, toml or xml", name)
+	}
+}
+
+// FromPath returns the format of a file from its extension.
+func FromPath(path string) (Format, bool) {
+	format, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+	return format, err == nil
+}
+
+// Detect guesses the format of data from its content. JSON is tried
+// before YAML, which accepts nearly anything, and TOML in between. Input
+// that YAML only reads as a single value is not detected.
+func Detect(data []byte) (Format, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	if len(trimmed) == 0 {
+		return "", fmt.Errorf("empty input")
+	}
+	switch trimmed[0] {
+	case '<':
+		return XML, nil
+	case '{':
+		return JSON, nil
+	case '[':
+		// A TOML table header or a JSON array
+		if !json.Valid(trimmed) && toml.Unmarshal(trimmed, &map[string]any{}) == nil {
+			return TOML, nil
+		}
+		return JSON, nil
+	}
+	if toml.Unmarshal(trimmed, &map[string]any{}) == nil {
+		return TOML, nil
+	}
+	// YAML reads any text as a plain string, which is not a document
+	var document yaml.Node
+	if yaml.Unmarshal(trimmed, &document) == nil && (len(document.Content) == 0 || document.Content[0].Kind == yaml.ScalarNode) {
+		return "", fmt.Errorf("unknown format, the input is not json, yaml, toml or xml")
+	}
+	return YAML, nil
+}
+
+// Options control Convert.
+type Options struct {
+	// From is the format of the input, detected when empty.
+	From Format
+	// To is the format of the output, the input format when empty.
+	To Format
+	// Indent is the number of spaces per level, DefaultIndent when zero.
+	Indent int
+	// Minify writes JSON and XML without any insignificant whitespace.
+	Minify bool
+}
+
+// Convert pretty-prints or minifies data, converting it to another format
+// if options ask for one. Invalid input is reported as a *SyntaxError.
+func Convert(data []byte, options Options) ([]byte, error) {
+	from := options.From
+	if from == "" {
+		detected, err := Detect(data)
+		if err != nil {
+			return nil, err
+		}
+		from = detected
+	}
+	to := options.To
+	if to == "" {
+		to = from
+	}
+	if options.Minify && to != JSON && to != XML {
+		return nil, fmt.Errorf("%s cannot be minified, only json and xml can", to)
+	}
+	indent := strings.Repeat(" ", options.Indent)
+	if options.Indent <= 0 {
+		indent = strings.Repeat(" ", DefaultIndent)
+	}
+
+	// Reformatting JSON and XML keeps the input as written, such as the
+	// precision of numbers and XML comments
+	switch {
+	case from == JSON && to == JSON:
+		if _, err := decodeJSON(data); err != nil {
+			return nil, err
+		}
+		return reformatJSON(data, indent, options.Minify)
+	case from == XML && to == XML:
+		return reformatXML(data, indent, options.Minify)
+	}
+
+	node, err := decode(data, from)
+	if err != nil {
+		return nil, err
+	}
+	return encode(node, to, indent, options.Minify)
+}
+
+// SyntaxError is invalid input, located by line and column when the
+// parser reports them.
+type SyntaxError struct {
+	Format  Format
+	Line    int
+	Column  int
+	Message string
+}
+
+func (err *SyntaxError) Error() string {
+	switch {
+	case err.Line > 0 && err.Column > 0:
+		return fmt.Sprintf("invalid %s at line %d, column %d: %s", err.Format, err.Line, err.Column, err.Message)
+	case err.Line > 0:
+		return fmt.Sprintf("invalid %s at line %d: %s", err.Format, err.Line, err.Message)
+	default:
+		return fmt.Sprintf("invalid %s: %s", err.Format, err.Message)
+	}
+}
+
+// position returns the line and column of the byte at offset in data,
+// both counted from 1.
+func position(data []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,213 @@
+package format
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{name: "json", want: JSON},
+		{name: "YAML", want: YAML},
+		{name: "yml", want: YAML},
+		{name: "toml", want: TOML},
+		{name: "xml", want: XML},
+		{name: "csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.name)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseFormat(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Format
+	}{
+		{name: "json object", input: `{"name": "qcli"}`, want: JSON},
+		{name: "json array", input: "[1, 2, 3]", want: JSON},
+		{name: "json with bom", input: "\ufeff  {\"a\": 1}", want: JSON},
+		{name: "xml", input: "<?xml version=\"1.0\"?><a/>", want: XML},
+		{name: "toml table", input: "[server]\nport = 8080\n", want: TOML},
+		{name: "toml keys", input: "name = \"qcli\"\n", want: TOML},
+		{name: "yaml", input: "name: qcli\ntags:\n  - cli\n", want: YAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect([]byte(tt.input))
+			if err != nil || got != tt.want {
+				t.Errorf("Detect() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{" \n", "hello world", "42"} {
+		if got, err := Detect([]byte(input)); err == nil {
+			t.Errorf("Detect(%q) = %q, want an error", input, got)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options Options
+		want    string
+	}{
+		{
+			name:  "pretty-print json keeps order and numbers",
+			input: `{"b":1.50,"a":[true,null]}`,
+			want:  "{\n  \"b\": 1.50,\n  \"a\": [\n    true,\n    null\n  ]\n}\n",
+		},
+		{
+			name:    "minify json",
+			input:   "{\n  \"a\": 1\n}",
+			options: Options{Minify: true},
+			want:    "{\"a\":1}\n",
+		},
+		{
+			name:    "indent json",
+			input:   `{"a":1}`,
+			options: Options{Indent: 4},
+			want:    "{\n    \"a\": 1\n}\n",
+		},
+		{
+			name:    "json to yaml",
+			input:   `{"name":"qcli","port":8080,"tags":["cli","ai"]}`,
+			options: Options{To: YAML},
+			want:    "name: qcli\nport: 8080\ntags:\n  - cli\n  - ai\n",
+		},
+		{
+			name:    "yaml to json",
+			input:   "name: qcli\nenabled: yes\nratio: 0.5\nempty:\n",
+			options: Options{To: JSON, Minify: true},
+			want:    "{\"name\":\"qcli\",\"enabled\":\"yes\",\"ratio\":0.5,\"empty\":null}\n",
+		},
+		{
+			name:    "toml to json",
+			input:   "[server]\nport = 8080\n",
+			options: Options{To: JSON, Minify: true},
+			want:    "{\"server\":{\"port\":8080}}\n",
+		},
+		{
+			name:    "json to toml",
+			input:   `{"server":{"port":8080}}`,
+			options: Options{To: TOML},
+			want:    "[server]\nport = 8080\n",
+		},
+		{
+			name:    "pretty-print xml",
+			input:   "<?xml version=\"1.0\"?>\n<a x=\"1\"><!-- note --><b>text</b><c/></a>",
+			options: Options{},
+			want:    "<?xml version=\"1.0\"?>\n<a x=\"1\">\n  <!-- note -->\n  <b>text</b>\n  <c></c>\n</a>\n",
+		},
+		{
+			name:    "minify xml keeps prefixes",
+			input:   "<s:a xmlns:s=\"urn:s\">\n  <s:b>1</s:b>\n</s:a>",
+			options: Options{Minify: true},
+			want:    "<s:a xmlns:s=\"urn:s\"><s:b>1</s:b></s:a>\n",
+		},
+		{
+			name:    "xml to json",
+			input:   "<book id=\"7\"><title>Go</title><tag>a</tag><tag>b</tag><note lang=\"en\">hi</note><empty/></book>",
+			options: Options{To: JSON, Minify: true},
+			want:    `{"book":{"-id":"7","title":"Go","tag":["a","b"],"note":{"-lang":"en","#text":"hi"},"empty":null}}` + "\n",
+		},
+		{
+			name:    "json to xml",
+			input:   `{"book":{"-id":"7","title":"Go","tag":["a","b"],"note":{"-lang":"en","#text":"hi"}}}`,
+			options: Options{To: XML, Minify: true},
+			want:    `<?xml version="1.0" encoding="UTF-8"?><book id="7"><title>Go</title><tag>a</tag><tag>b</tag><note lang="en">hi</note></book>` + "\n",
+		},
+		{
+			name:    "json array to xml",
+			input:   `[1, 2]`,
+			options: Options{To: XML, Minify: true},
+			want:    `<?xml version="1.0" encoding="UTF-8"?><root><item>1</item><item>2</item></root>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert([]byte(tt.input), tt.options)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Convert() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		options    Options
+		wantLine   int
+		wantColumn int
+		wantErr    string
+	}{
+		{name: "json", input: "{\n  \"a\": 1,\n  \"b\" 2\n}", wantLine: 3, wantColumn: 7, wantErr: "invalid json at line 3, column 7"},
+		{name: "json end", input: "{\n  \"a\": [1, 2\n", options: Options{From: JSON}, wantLine: 2, wantColumn: 13, wantErr: "unexpected end of input"},
+		{name: "yaml", input: "a: 1\nb: c: d\n", options: Options{From: YAML}, wantLine: 2, wantErr: "invalid yaml at line 2: mapping values"},
+		{name: "yaml documents", input: "a: 1\n---\nb: 2\n", options: Options{From: YAML}, wantLine: 2, wantErr: "only one document is supported"},
+		{name: "toml", input: "[server]\nport = \n", options: Options{From: TOML}, wantLine: 2, wantColumn: 8, wantErr: "invalid toml at line 2, column 8"},
+		{name: "xml mismatched", input: "<a>\n  <b></c>\n</a>", wantLine: 2, wantErr: "unexpected </c>, expected </b>"},
+		{name: "xml unclosed", input: "<a>\n  <b></b>\n", wantLine: 3, wantErr: "element <a> is never closed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Convert([]byte(tt.input), tt.options)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Convert() error = %v, want a *SyntaxError", err)
+			}
+			if syntaxErr.Line != tt.wantLine || tt.wantColumn > 0 && syntaxErr.Column != tt.wantColumn {
+				t.Errorf("position = %d:%d, want %d:%d", syntaxErr.Line, syntaxErr.Column, tt.wantLine, tt.wantColumn)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Convert() error = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertUnsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options Options
+		wantErr string
+	}{
+		{name: "minify yaml", input: "a: 1", options: Options{From: YAML, Minify: true}, wantErr: "yaml cannot be minified"},
+		{name: "list to toml", input: "[1, 2]", options: Options{To: TOML}, wantErr: "toml needs a table"},
+		{name: "null to toml", input: `{"a": 1.10, "b": {"c": [1, null]}}`, options: Options{To: TOML}, wantErr: "toml cannot represent null at b.c[1]"},
+		{name: "bad xml name", input: `{"1a": 1}`, options: Options{To: XML}, wantErr: `"1a" cannot be an xml element name`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Convert([]byte(tt.input), tt.options)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Convert() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
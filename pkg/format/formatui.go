@@ -0,0 +1,229 @@
+package format
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	titleStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("36")).Bold(true)
+	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Bold(true)
+	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	noticeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
+	inputStyle  = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("36"))
+)
+
+// Model converts the document typed or pasted into its input as it
+// changes, showing the highlighted result below.
+type Model struct {
+	input    textarea.Model
+	viewport viewport.Model
+	style    string
+	indent   int
+	// to is the output format, "" for the format of the input
+	to       Format
+	minify   bool
+	from     Format
+	output   string
+	err      string
+	notice   string
+	width    int
+	height   int
+	quitting bool
+}
+
+// New returns the formatter screen, indenting output by indent spaces and
+// highlighting it with the chroma style.
+func New(style string, indent int) *Model {
+	input := textarea.New()
+	input.Placeholder = "Paste JSON, YAML, TOML or XML, or type @path to load a file"
+	input.ShowLineNumbers = true
+	input.CharLimit = 0
+	input.Focus()
+
+	formatModel := &Model{
+		input:    input,
+		viewport: viewport.New(0, 0),
+		style:    style,
+		indent:   indent,
+	}
+	formatModel.viewport.SetContent(noticeStyle.Render("The formatted document shows up here."))
+	return formatModel
+}
+
+func (formatModel *Model) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (formatModel *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		formatModel.width, formatModel.height = msg.Width, msg.Height
+		formatModel.resize()
+		return formatModel, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			formatModel.quitting = true
+			return formatModel, tea.Quit
+		case "tab":
+			formatModel.to = nextFormat(formatModel.to)
+			formatModel.convert()
+			return formatModel, nil
+		case "ctrl+n":
+			formatModel.minify = !formatModel.minify
+			formatModel.convert()
+			return formatModel, nil
+		case "ctrl+y":
+			formatModel.copyOutput()
+			return formatModel, nil
+		case "pgup", "pgdown":
+			var cmd tea.Cmd
+			formatModel.viewport, cmd = formatModel.viewport.Update(msg)
+			return formatModel, cmd
+		}
+	}
+
+	before := formatModel.input.Value()
+	var cmd tea.Cmd
+	formatModel.input, cmd = formatModel.input.Update(msg)
+	if formatModel.input.Value() != before {
+		formatModel.notice = ""
+		formatModel.convert()
+	}
+	return formatModel, cmd
+}
+
+func (formatModel *Model) View() string {
+	to := "same format"
+	if formatModel.to != "" {
+		to = string(formatModel.to)
+	}
+	status := "input: -"
+	if formatModel.from != "" {
+		status = "input: " + string(formatModel.from)
+	}
+	status += " • output: " + to
+	if formatModel.minify {
+		status += " • minified"
+	}
+	line := statusStyle.Render(status)
+	if formatModel.notice != "" {
+		line += "  " + noticeStyle.Render(formatModel.notice)
+	}
+	help := noticeStyle.Render("tab: output format • ctrl+n: minify • ctrl+y: copy • pgup/pgdown: scroll • esc: quit")
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Formatter"),
+		inputStyle.Render(formatModel.input.View()),
+		line,
+		formatModel.viewport.View(),
+		help,
+	)
+}
+
+// Quitting reports whether the user left the formatter.
+func (formatModel *Model) Quitting() bool {
+	return formatModel.quitting
+}
+
+// nextFormat cycles from the input format through every format.
+func nextFormat(current Format) Format {
+	if current == "" {
+		return Formats[0]
+	}
+	for i, format := range Formats {
+		if format == current && i+1 < len(Formats) {
+			return Formats[i+1]
+		}
+	}
+	return ""
+}
+
+// source returns the document to convert, read from a file when the input
+// is a single @path line, with the format of its extension.
+func (formatModel *Model) source() ([]byte, Format, error) {
+	value := strings.TrimSpace(formatModel.input.Value())
+	if !strings.HasPrefix(value, "@") || strings.Contains(value, "\n") {
+		return []byte(formatModel.input.Value()), "", nil
+	}
+	path := strings.TrimPrefix(value, "@")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	from, _ := FromPath(path)
+	return data, from, nil
+}
+
+// convert formats the input with the current options and shows the result
+// or the error.
+func (formatModel *Model) convert() {
+	formatModel.from, formatModel.output, formatModel.err = "", "", ""
+	data, from, err := formatModel.source()
+	if err == nil && strings.TrimSpace(string(data)) == "" {
+		formatModel.viewport.SetContent("")
+		return
+	}
+	if err == nil && from == "" {
+		from, err = Detect(data)
+	}
+	var output []byte
+	if err == nil {
+		formatModel.from = from
+		output, err = Convert(data, Options{From: from, To: formatModel.to, Indent: formatModel.indent, Minify: formatModel.minify})
+	}
+	if err != nil {
+		formatModel.err = err.Error()
+	} else {
+		formatModel.output = string(output)
+	}
+	formatModel.render()
+}
+
+func (formatModel *Model) copyOutput() {
+	if formatModel.output == "" {
+		formatModel.notice = "nothing to copy yet"
+		return
+	}
+	if err := clipboard.WriteAll(formatModel.output); err != nil {
+		formatModel.notice = fmt.Sprintf("failed to copy: %v", err)
+		return
+	}
+	formatModel.notice = "Copied the output to the clipboard"
+}
+
+func (formatModel *Model) resize() {
+	// The input takes a third of the screen, the title, border, status
+	// and help lines take 5
+	inputHeight := max(formatModel.height/3, 3)
+	formatModel.input.SetWidth(max(formatModel.width-2, 10))
+	formatModel.input.SetHeight(inputHeight)
+	formatModel.viewport.Width = formatModel.width
+	formatModel.viewport.Height = max(formatModel.height-inputHeight-5, 1)
+	if formatModel.output != "" || formatModel.err != "" {
+		formatModel.render()
+	}
+}
+
+func (formatModel *Model) render() {
+	if formatModel.err != "" {
+		wrapped := lipgloss.NewStyle().Width(formatModel.viewport.Width).Render(formatModel.err)
+		formatModel.viewport.SetContent(errorStyle.Render(wrapped))
+		return
+	}
+	to := formatModel.to
+	if to == "" {
+		to = formatModel.from
+	}
+	formatModel.viewport.SetContent(Highlight(formatModel.output, to, formatModel.style))
+}
@@ -0,0 +1,36 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Style returns the chroma style matching a configured chat theme, picking
+// one from the terminal background for "auto".
+func Style(theme string) string {
+	switch theme {
+	case "light":
+		return "github"
+	case "dracula":
+		return "dracula"
+	case "tokyo-night":
+		return "tokyonight-night"
+	case "", "auto":
+		if !lipgloss.HasDarkBackground() {
+			return "github"
+		}
+	}
+	return "monokai"
+}
+
+// Highlight colors text of the given format for a 256 color terminal,
+// returning it unchanged if it cannot.
+func Highlight(text string, format Format, style string) string {
+	var builder strings.Builder
+	if err := quick.Highlight(&builder, text, string(format), "terminal256", style); err != nil {
+		return text
+	}
+	return builder.String()
+}
@@ -0,0 +1,336 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// XML elements convert to mappings the way most converters do: attributes
+// become keys prefixed with "-", text next to child elements goes under
+// "#text" and repeated elements become lists.
+const (
+	attributePrefix = "-"
+	textKey         = "#text"
+)
+
+// readXML passes the tokens of data to handle, checking that there is a
+// single root element and that elements are closed in order.
+func readXML(data []byte, handle func(token xml.Token) error) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	fail := func(message string) error {
+		line, column := decoder.InputPos()
+		return &SyntaxError{Format: XML, Line: line, Column: column, Message: message}
+	}
+
+	var open []xml.Name
+	seenRoot := false
+	for {
+		// RawToken keeps namespace prefixes as written
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return fail(syntaxErr.Msg)
+			}
+			return fail(err.Error())
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if len(open) == 0 && seenRoot {
+				return fail(fmt.Sprintf("second root element <%s>", qualifiedName(token.Name)))
+			}
+			open = append(open, token.Name)
+			seenRoot = true
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != token.Name {
+				expected := "no open element"
+				if len(open) > 0 {
+					expected = fmt.Sprintf("</%s>", qualifiedName(open[len(open)-1]))
+				}
+				return fail(fmt.Sprintf("unexpected </%s>, expected %s", qualifiedName(token.Name), expected))
+			}
+			open = open[:len(open)-1]
+		case xml.CharData:
+			if len(open) == 0 && len(bytes.TrimSpace(token)) > 0 {
+				return fail("text outside the root element")
+			}
+		}
+		if err := handle(xml.CopyToken(token)); err != nil {
+			return err
+		}
+	}
+	if len(open) > 0 {
+		return fail(fmt.Sprintf("element <%s> is never closed", qualifiedName(open[len(open)-1])))
+	}
+	if !seenRoot {
+		return fail("no root element")
+	}
+	return nil
+}
+
+// qualifiedName returns name with its namespace prefix, as written.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// reformatXML indents or minifies XML, dropping only the whitespace
+// between elements.
+func reformatXML(data []byte, indent string, minify bool) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := xml.NewEncoder(&buffer)
+	if !minify {
+		encoder.Indent("", indent)
+	}
+	depth := 0
+	err := readXML(data, func(token xml.Token) error {
+		// Names are written as read, so the encoder does not add
+		// namespace declarations of its own
+		switch typed := token.(type) {
+		case xml.StartElement:
+			depth++
+			typed.Name = xml.Name{Local: qualifiedName(typed.Name)}
+			for i, attr := range typed.Attr {
+				typed.Attr[i].Name = xml.Name{Local: qualifiedName(attr.Name)}
+			}
+			token = typed
+		case xml.EndElement:
+			depth--
+			token = xml.EndElement{Name: xml.Name{Local: qualifiedName(typed.Name)}}
+		case xml.CharData:
+			if len(bytes.TrimSpace(typed)) == 0 {
+				return nil
+			}
+		case xml.Comment:
+			// The encoder only indents elements
+			if !minify && depth > 0 {
+				if err := encoder.EncodeToken(xml.CharData("\n" + strings.Repeat(indent, depth))); err != nil {
+					return err
+				}
+			}
+		case xml.ProcInst:
+			if typed.Target == "xml" && buffer.Len() == 0 {
+				// Written directly, since the encoder would put the root
+				// element on the same line
+				fmt.Fprintf(&buffer, "<?xml %s?>", typed.Inst)
+				if !minify {
+					buffer.WriteByte('\n')
+				}
+				return nil
+			}
+		}
+		return encoder.EncodeToken(token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write xml: %v", err)
+	}
+	buffer.WriteByte('\n')
+	return buffer.Bytes(), nil
+}
+
+// xmlElement is an element being read by decodeXML.
+type xmlElement struct {
+	name string
+	node *yaml.Node
+	text strings.Builder
+}
+
+// value returns the element as a string when it only holds text, as
+// null when it is empty and as a mapping otherwise.
+func (element *xmlElement) value() *yaml.Node {
+	text := strings.TrimSpace(element.text.String())
+	if len(element.node.Content) == 0 {
+		if text == "" {
+			return scalar("!!null", "")
+		}
+		return scalar("!!str", text)
+	}
+	if text != "" {
+		addXMLChild(element.node, textKey, scalar("!!str", text))
+	}
+	return element.node
+}
+
+// addXMLChild adds value under key, turning repeated keys into a list.
+func addXMLChild(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		existing := mapping.Content[i+1]
+		if existing.Kind != yaml.SequenceNode {
+			existing = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{existing}}
+			mapping.Content[i+1] = existing
+		}
+		existing.Content = append(existing.Content, value)
+		return
+	}
+	mapping.Content = append(mapping.Content, scalar("!!str", key), value)
+}
+
+func decodeXML(data []byte) (*yaml.Node, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	var stack []*xmlElement
+	err := readXML(data, func(token xml.Token) error {
+		switch token := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{
+				name: qualifiedName(token.Name),
+				node: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"},
+			}
+			for _, attr := range token.Attr {
+				addXMLChild(element.node, attributePrefix+qualifiedName(attr.Name), scalar("!!str", attr.Value))
+			}
+			stack = append(stack, element)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(token)
+			}
+		case xml.EndElement:
+			element := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			parent := root
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1].node
+			}
+			addXMLChild(parent, element.name, element.value())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// encodeXML writes node as an XML document. A mapping with a single key
+// names the root element, anything else is wrapped in <root>.
+func encodeXML(node *yaml.Node, indent string, minify bool) ([]byte, error) {
+	node = resolve(node)
+	name, value := "root", node
+	switch {
+	case node.Kind == yaml.MappingNode && len(node.Content) == 2 && resolve(node.Content[1]).Kind != yaml.SequenceNode:
+		name, value = node.Content[0].Value, node.Content[1]
+	case node.Kind == yaml.SequenceNode:
+		value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{scalar("!!str", "item"), node}}
+	}
+
+	var buffer bytes.Buffer
+	header := xml.Header
+	if minify {
+		header = strings.TrimSuffix(header, "\n")
+	}
+	buffer.WriteString(header)
+	encoder := xml.NewEncoder(&buffer)
+	if !minify {
+		encoder.Indent("", indent)
+	}
+	if err := writeXMLElement(encoder, name, value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write xml: %v", err)
+	}
+	buffer.WriteByte('\n')
+	return buffer.Bytes(), nil
+}
+
+func writeXMLElement(encoder *xml.Encoder, name string, node *yaml.Node) error {
+	node = resolve(node)
+	if node.Kind == yaml.SequenceNode {
+		for _, item := range node.Content {
+			if err := writeXMLElement(encoder, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !validXMLName(name) {
+		return fmt.Errorf("%q cannot be an xml element name", name)
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	var children []*yaml.Node
+	var text string
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, resolve(node.Content[i+1])
+			switch {
+			case key == textKey && value.Kind == yaml.ScalarNode:
+				text = value.Value
+			case strings.HasPrefix(key, attributePrefix) && value.Kind == yaml.ScalarNode:
+				attrName := strings.TrimPrefix(key, attributePrefix)
+				if !validXMLName(attrName) {
+					return fmt.Errorf("%q cannot be an xml attribute name", attrName)
+				}
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attrName}, Value: value.Value})
+			default:
+				children = append(children, node.Content[i], value)
+			}
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!null" {
+			text = node.Value
+		}
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if text != "" {
+		if err := encoder.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	for i := 0; i+1 < len(children); i += 2 {
+		if err := writeXMLElement(encoder, children[i].Value, children[i+1]); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+func resolve(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode || node.Kind == yaml.DocumentNode {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else {
+			node = node.Content[0]
+		}
+	}
+	return node
+}
+
+// validXMLName accepts the names XML allows, prefixed or not.
+func validXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") && name != "xmlns" && !strings.HasPrefix(name, "xmlns:") && !strings.HasPrefix(name, "xml:") {
+		return false
+	}
+	for i, r := range name {
+		if unicode.IsLetter(r) || r == '_' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.' || r == ':') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/format"
+)
+
+// timeClaims are the registered claims holding times, in the order they
+// are shown.
+var timeClaims = []string{"exp", "iat", "nbf"}
+
+// Decoded is a token split into its parts. Decoding does not check the
+// signature, see Verify.
+type Decoded struct {
+	Header map[string]any
+	Claims map[string]any
+	// HeaderJSON and ClaimsJSON are the parts indented, with their keys
+	// in the order of the token
+	HeaderJSON string
+	ClaimsJSON string
+	Signature  []byte
+}
+
+// Time is a time claim of a token.
+type Time struct {
+	Claim string
+	Time  time.Time
+}
+
+// Decode splits token into its header, claims and signature. A leading
+// "Bearer " is ignored, so Authorization headers can be pasted as is.
+func Decode(token string) (*Decoded, error) {
+	parts := strings.Split(compact(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("a token has 3 parts separated by dots, this one has %d", len(parts))
+	}
+
+	decoded := &Decoded{}
+	names := []string{"header", "claims", "signature"}
+	var raw [3][]byte
+	for i, part := range parts {
+		data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the %s: %v", names[i], err)
+		}
+		raw[i] = data
+	}
+	decoded.Signature = raw[2]
+
+	for i, target := range []*map[string]any{&decoded.Header, &decoded.Claims} {
+		decoder := json.NewDecoder(bytes.NewReader(raw[i]))
+		decoder.UseNumber()
+		if err := decoder.Decode(target); err != nil {
+			return nil, fmt.Errorf("the %s is not a JSON object: %v", names[i], err)
+		}
+		if *target == nil {
+			return nil, fmt.Errorf("the %s is not a JSON object", names[i])
+		}
+	}
+	header, err := format.Convert(raw[0], format.Options{From: format.JSON})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header: %v", err)
+	}
+	claims, err := format.Convert(raw[1], format.Options{From: format.JSON})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the claims: %v", err)
+	}
+	decoded.HeaderJSON = strings.TrimSuffix(string(header), "\n")
+	decoded.ClaimsJSON = strings.TrimSuffix(string(claims), "\n")
+	return decoded, nil
+}
+
+// compact removes whitespace and a "Bearer" prefix from token.
+func compact(token string) string {
+	token = strings.Join(strings.Fields(token), "")
+	return strings.TrimPrefix(strings.TrimPrefix(token, "Bearer"), "bearer")
+}
+
+// Algorithm returns the alg of the header.
+func (decoded *Decoded) Algorithm() string {
+	alg, _ := decoded.Header["alg"].(string)
+	return alg
+}
+
+// Times returns the exp, iat and nbf claims that are numbers.
+func (decoded *Decoded) Times() []Time {
+	var times []Time
+	for _, claim := range timeClaims {
+		if at, ok := decoded.time(claim); ok {
+			times = append(times, Time{Claim: claim, Time: at})
+		}
+	}
+	return times
+}
+
+func (decoded *Decoded) time(claim string) (time.Time, bool) {
+	number, ok := decoded.Claims[claim].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	// Nanoseconds since 1970 overflow an int64 in 2262
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second))), true
+}
+
+// Describe lists the time claims of decoded as local times relative to
+// now, one per line.
+func Describe(decoded *Decoded, now time.Time) string {
+	var lines []string
+	for _, claim := range decoded.Times() {
+		lines = append(lines, fmt.Sprintf("%s  %s (%s)", claim.Claim, claim.Time.Local().Format("2006-01-02 15:04:05 MST"), Relative(claim.Time, now)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Valid fails when the token has expired or is not valid yet at now.
+func (decoded *Decoded) Valid(now time.Time) error {
+	if exp, ok := decoded.time("exp"); ok && !now.Before(exp) {
+		return fmt.Errorf("the token expired %s", Relative(exp, now))
+	}
+	if nbf, ok := decoded.time("nbf"); ok && now.Before(nbf) {
+		return fmt.Errorf("the token is only valid %s", Relative(nbf, now))
+	}
+	return nil
+}
+
+// Warnings lists what is wrong or unusual about the token at now, such as
+// being expired or never expiring.
+func (decoded *Decoded) Warnings(now time.Time) []string {
+	var warnings []string
+	if err := decoded.Valid(now); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	for _, claim := range timeClaims {
+		if _, present := decoded.Claims[claim]; present {
+			if _, ok := decoded.time(claim); !ok {
+				warnings = append(warnings, fmt.Sprintf("%s is not a number of seconds", claim))
+			}
+		}
+	}
+	if _, present := decoded.Claims["exp"]; !present {
+		warnings = append(warnings, "there is no exp claim, the token never expires")
+	}
+	if iat, ok := decoded.time("iat"); ok && iat.After(now) {
+		warnings = append(warnings, fmt.Sprintf("the token claims to be issued %s", Relative(iat, now)))
+	}
+	if alg := decoded.Algorithm(); strings.EqualFold(alg, "none") || alg == "" {
+		warnings = append(warnings, "the token is not signed")
+	}
+	return warnings
+}
+
+// Relative describes at from now, such as "in 2h5m" or "3d4h ago".
+func Relative(at time.Time, now time.Time) string {
+	duration := at.Sub(now)
+	if duration < 0 {
+		return shortDuration(-duration) + " ago"
+	}
+	return "in " + shortDuration(duration)
+}
+
+// shortDuration keeps the largest unit of duration and the one after it.
+func shortDuration(duration time.Duration) string {
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+	for i, unit := range units {
+		count := duration / unit.size
+		if count == 0 {
+			continue
+		}
+		text := fmt.Sprintf("%d%s", count, unit.name)
+		if i+1 < len(units) {
+			if next := (duration - count*unit.size) / units[i+1].size; next > 0 {
+				text += fmt.Sprintf("%d%s", next, units[i+1].name)
+			}
+		}
+		return text
+	}
+	return "0s"
+}
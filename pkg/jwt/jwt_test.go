@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func encodeToken(header string, claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(header)) + "." + encode([]byte(claims)) + "." + encode([]byte("signature"))
+}
+
+func TestDecode(t *testing.T) {
+	token := encodeToken(`{"alg":"HS256","typ":"JWT"}`, `{"sub":"42","name":"Ada","exp":1700003600,"iat":1700000000}`)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "token", token: token},
+		{name: "bearer header", token: "Bearer " + token},
+		{name: "wrapped lines", token: token[:20] + "\n  " + token[20:]},
+		{name: "two parts", token: "abc.def", wantErr: "this one has 2"},
+		{name: "bad base64", token: "a*b.c.d", wantErr: "failed to decode the header"},
+		{name: "claims not an object", token: encodeToken(`{"alg":"HS256"}`, `[1]`), wantErr: "the claims is not a JSON object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Decode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded.Algorithm() != "HS256" || string(decoded.Signature) != "signature" {
+				t.Errorf("Decode() = %q, %q", decoded.Algorithm(), decoded.Signature)
+			}
+			wantClaims := "{\n  \"sub\": \"42\",\n  \"name\": \"Ada\",\n  \"exp\": 1700003600,\n  \"iat\": 1700000000\n}"
+			if decoded.ClaimsJSON != wantClaims {
+				t.Errorf("ClaimsJSON = %q, want %q", decoded.ClaimsJSON, wantClaims)
+			}
+			times := decoded.Times()
+			if len(times) != 2 || times[0].Claim != "exp" || times[0].Time.Unix() != 1700003600 || times[1].Claim != "iat" {
+				t.Errorf("Times() = %v", times)
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name   string
+		header string
+		claims string
+		want   []string
+	}{
+		{name: "valid", header: `{"alg":"RS256"}`, claims: `{"exp":1700003600,"iat":1699999000}`},
+		{name: "expired", header: `{"alg":"RS256"}`, claims: `{"exp":1699989200}`, want: []string{"the token expired 3h ago"}},
+		{name: "not yet valid", header: `{"alg":"RS256"}`, claims: `{"exp":1700090000,"nbf":1700000090}`, want: []string{"the token is only valid in 1m30s"}},
+		{name: "no exp", header: `{"alg":"HS256"}`, claims: `{"sub":"42"}`, want: []string{"there is no exp claim, the token never expires"}},
+		{name: "exp not a number", header: `{"alg":"HS256"}`, claims: `{"exp":"tomorrow"}`, want: []string{"exp is not a number of seconds"}},
+		{name: "issued in the future", header: `{"alg":"HS256"}`, claims: `{"exp":1700090000,"iat":1700086400}`, want: []string{"the token claims to be issued in 1d"}},
+		{name: "far future", header: `{"alg":"RS256"}`, claims: `{"exp":253402300799,"iat":1699999000.5}`},
+		{name: "unsigned", header: `{"alg":"none"}`, claims: `{"exp":1700090000}`, want: []string{"the token is not signed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(encodeToken(tt.header, tt.claims))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := decoded.Warnings(now)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Warnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimes(t *testing.T) {
+	decoded, err := Decode(encodeToken(`{"alg":"HS256"}`, `{"exp":253402300799,"iat":1700000000.25}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	times := decoded.Times()
+	want := []time.Time{time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), time.Unix(1700000000, 250000000)}
+	if len(times) != len(want) {
+		t.Fatalf("Times() = %v, want %v", times, want)
+	}
+	for i, claim := range times {
+		if !claim.Time.Equal(want[i]) {
+			t.Errorf("Times()[%d] = %v, want %v", i, claim.Time, want[i])
+		}
+	}
+}
+
+func TestRelative(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		offset time.Duration
+		want   string
+	}{
+		{offset: 0, want: "in 0s"},
+		{offset: 45 * time.Second, want: "in 45s"},
+		{offset: 2*time.Hour + 5*time.Minute + 3*time.Second, want: "in 2h5m"},
+		{offset: -(3*24*time.Hour + 4*time.Hour), want: "3d4h ago"},
+		{offset: -(24*time.Hour + 30*time.Minute), want: "1d ago"},
+	}
+
+	for _, tt := range tests {
+		if got := Relative(now.Add(tt.offset), now); got != tt.want {
+			t.Errorf("Relative(%v) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
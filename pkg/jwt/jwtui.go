@@ -0,0 +1,341 @@
+package jwt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/format"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	titleStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("36")).Bold(true)
+	headerStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Bold(true)
+	validStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	noticeStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
+	inputStyle   = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("36"))
+)
+
+// Model decodes the token pasted into its input as it changes and checks
+// its signature with the secret or key file of its key input. In sign mode
+// it signs the claims of its claims input with that key instead.
+type Model struct {
+	token    textarea.Model
+	claims   textarea.Model
+	key      textinput.Model
+	viewport viewport.Model
+	style    string
+	decoded  *Decoded
+	// signing switches from decoding token to signing claims with the
+	// algorithm of Algorithms at index algorithm
+	signing   bool
+	algorithm int
+	signed    string
+	notice    string
+	width     int
+	height    int
+	quitting  bool
+}
+
+// New returns the JWT screen, highlighting JSON with the chroma style.
+func New(style string) *Model {
+	token := textarea.New()
+	token.Placeholder = "Paste a token, or an Authorization header"
+	token.ShowLineNumbers = false
+	token.CharLimit = 0
+	token.Focus()
+
+	claims := textarea.New()
+	claims.Placeholder = `Claims to sign, as a JSON object such as {"sub":"42"}`
+	claims.ShowLineNumbers = false
+	claims.CharLimit = 0
+
+	// The key is masked unless it names a file, see updateEcho
+	key := textinput.New()
+	key.Placeholder = "HMAC secret, or path to a PEM or JWKS file, to check the signature"
+	key.Prompt = "▶ "
+	key.EchoMode = textinput.EchoPassword
+	key.EchoCharacter = '•'
+
+	jwtModel := &Model{
+		token:    token,
+		claims:   claims,
+		key:      key,
+		viewport: viewport.New(0, 0),
+		style:    style,
+	}
+	jwtModel.viewport.SetContent(noticeStyle.Render("The header and claims of the token show up here."))
+	return jwtModel
+}
+
+func (jwtModel *Model) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (jwtModel *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		jwtModel.width, jwtModel.height = msg.Width, msg.Height
+		jwtModel.resize()
+		return jwtModel, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			jwtModel.quitting = true
+			return jwtModel, tea.Quit
+		case "tab":
+			input := jwtModel.input()
+			if input.Focused() {
+				input.Blur()
+				return jwtModel, jwtModel.key.Focus()
+			}
+			jwtModel.key.Blur()
+			return jwtModel, input.Focus()
+		case "ctrl+s":
+			return jwtModel, jwtModel.toggleSigning()
+		case "ctrl+n":
+			if jwtModel.signing {
+				jwtModel.algorithm = (jwtModel.algorithm + 1) % len(Algorithms)
+				jwtModel.render()
+			}
+			return jwtModel, nil
+		case "ctrl+y":
+			jwtModel.copyResult()
+			return jwtModel, nil
+		case "pgup", "pgdown":
+			var cmd tea.Cmd
+			jwtModel.viewport, cmd = jwtModel.viewport.Update(msg)
+			return jwtModel, cmd
+		}
+	}
+
+	input := jwtModel.input()
+	inputBefore, keyBefore := input.Value(), jwtModel.key.Value()
+	var inputCmd, keyCmd tea.Cmd
+	*input, inputCmd = input.Update(msg)
+	jwtModel.key, keyCmd = jwtModel.key.Update(msg)
+	if input.Value() != inputBefore || jwtModel.key.Value() != keyBefore {
+		jwtModel.notice = ""
+		jwtModel.updateEcho()
+		jwtModel.render()
+	}
+	return jwtModel, tea.Batch(inputCmd, keyCmd)
+}
+
+func (jwtModel *Model) View() string {
+	title := titleStyle.Render("JWT")
+	help := noticeStyle.Render("tab: token/key • ctrl+s: sign • ctrl+y: copy claims • pgup/pgdown: scroll • esc: quit")
+	if jwtModel.signing {
+		title += noticeStyle.Render(" signing with " + Algorithms[jwtModel.algorithm])
+		help = noticeStyle.Render("tab: claims/key • ctrl+n: algorithm • ctrl+s: decode • ctrl+y: copy token • esc: quit")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		inputStyle.Render(jwtModel.input().View()),
+		inputStyle.Render(jwtModel.key.View()),
+		jwtModel.viewport.View(),
+		noticeStyle.Render(jwtModel.notice),
+		help,
+	)
+}
+
+// Quitting reports whether the user left the JWT screen.
+func (jwtModel *Model) Quitting() bool {
+	return jwtModel.quitting
+}
+
+// input returns the token input, or the claims input in sign mode.
+func (jwtModel *Model) input() *textarea.Model {
+	if jwtModel.signing {
+		return &jwtModel.claims
+	}
+	return &jwtModel.token
+}
+
+// toggleSigning switches between decoding and signing, keeping the focus
+// on the key input when it has it.
+func (jwtModel *Model) toggleSigning() tea.Cmd {
+	focused := jwtModel.input().Focused()
+	jwtModel.input().Blur()
+	jwtModel.signing = !jwtModel.signing
+	jwtModel.notice = ""
+	if jwtModel.signing {
+		jwtModel.key.Placeholder = "HMAC secret, or path to a PEM file with the private key, to sign with"
+	} else {
+		jwtModel.key.Placeholder = "HMAC secret, or path to a PEM or JWKS file, to check the signature"
+	}
+	jwtModel.render()
+	if focused {
+		return jwtModel.input().Focus()
+	}
+	return nil
+}
+
+// keyFile returns the path the key input names, or "" when it holds a
+// secret.
+func (jwtModel *Model) keyFile() string {
+	value := strings.TrimSpace(jwtModel.key.Value())
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		return value
+	}
+	return ""
+}
+
+// updateEcho shows the key input when it names a file and masks secrets.
+func (jwtModel *Model) updateEcho() {
+	if jwtModel.keyFile() != "" {
+		jwtModel.key.EchoMode = textinput.EchoNormal
+	} else {
+		jwtModel.key.EchoMode = textinput.EchoPassword
+	}
+}
+
+// keys returns the keys of the key input, read from a file when it names
+// one and taken as an HMAC secret otherwise.
+func (jwtModel *Model) keys() ([]Key, error) {
+	if path := jwtModel.keyFile(); path != "" {
+		return LoadKeys(path)
+	}
+	return []Key{SecretKey(jwtModel.key.Value())}, nil
+}
+
+func (jwtModel *Model) render() {
+	if jwtModel.signing {
+		jwtModel.renderSigned()
+		return
+	}
+	jwtModel.decoded = nil
+	if strings.TrimSpace(jwtModel.token.Value()) == "" {
+		jwtModel.viewport.SetContent("")
+		return
+	}
+	decoded, err := Decode(jwtModel.token.Value())
+	if err != nil {
+		jwtModel.setContent(errorStyle.Render(err.Error()))
+		return
+	}
+	jwtModel.decoded = decoded
+
+	now := time.Now()
+	var sections []string
+	sections = append(sections, headerStyle.Render("Header")+"\n"+format.Highlight(decoded.HeaderJSON, format.JSON, jwtModel.style))
+	sections = append(sections, headerStyle.Render("Claims")+"\n"+format.Highlight(decoded.ClaimsJSON, format.JSON, jwtModel.style))
+	if times := Describe(decoded, now); times != "" {
+		sections = append(sections, headerStyle.Render("Times")+"\n"+times)
+	}
+
+	signature := noticeStyle.Render("Not checked, enter a secret or key file")
+	if jwtModel.key.Value() != "" {
+		keys, err := jwtModel.keys()
+		if err == nil {
+			_, err = Verify(jwtModel.token.Value(), keys)
+		}
+		if err != nil {
+			signature = errorStyle.Render("✗ " + err.Error())
+		} else {
+			signature = validStyle.Render("✓ Verified with " + decoded.Algorithm())
+		}
+	}
+	sections = append(sections, headerStyle.Render("Signature")+"\n"+signature)
+
+	if warnings := decoded.Warnings(now); len(warnings) > 0 {
+		var lines []string
+		for _, warning := range warnings {
+			lines = append(lines, warningStyle.Render("⚠ "+warning))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	jwtModel.setContent(strings.Join(sections, "\n\n"))
+}
+
+// renderSigned signs the claims input and shows the token and its header.
+func (jwtModel *Model) renderSigned() {
+	jwtModel.signed = ""
+	if strings.TrimSpace(jwtModel.claims.Value()) == "" {
+		jwtModel.setContent(noticeStyle.Render("The signed token shows up here."))
+		return
+	}
+	if jwtModel.key.Value() == "" {
+		jwtModel.setContent(noticeStyle.Render("Enter a secret or the path of a private key file to sign with"))
+		return
+	}
+	keys, err := jwtModel.keys()
+	if err == nil && len(keys) > 1 {
+		err = fmt.Errorf("%s holds %d keys, sign with a file holding one", jwtModel.keyFile(), len(keys))
+	}
+	var token string
+	if err == nil {
+		token, err = Sign([]byte(jwtModel.claims.Value()), SignOptions{Algorithm: Algorithms[jwtModel.algorithm], Key: keys[0]})
+	}
+	if err != nil {
+		jwtModel.setContent(errorStyle.Render("✗ " + err.Error()))
+		return
+	}
+	jwtModel.signed = token
+
+	sections := []string{headerStyle.Render("Token") + "\n" + token}
+	if decoded, err := Decode(token); err == nil {
+		sections = append(sections, headerStyle.Render("Header")+"\n"+format.Highlight(decoded.HeaderJSON, format.JSON, jwtModel.style))
+		if warnings := decoded.Warnings(time.Now()); len(warnings) > 0 {
+			var lines []string
+			for _, warning := range warnings {
+				lines = append(lines, warningStyle.Render("⚠ "+warning))
+			}
+			sections = append(sections, strings.Join(lines, "\n"))
+		}
+	}
+	jwtModel.setContent(strings.Join(sections, "\n\n"))
+}
+
+func (jwtModel *Model) setContent(content string) {
+	wrapped := lipgloss.NewStyle().Width(jwtModel.viewport.Width).Render(content)
+	jwtModel.viewport.SetContent(wrapped)
+}
+
+// copyResult copies the claims of the decoded token, or the signed token
+// in sign mode.
+func (jwtModel *Model) copyResult() {
+	text, what := jwtModel.signed, "token"
+	if !jwtModel.signing {
+		text, what = "", "claims"
+		if jwtModel.decoded != nil {
+			text = jwtModel.decoded.ClaimsJSON
+		}
+	}
+	if text == "" {
+		jwtModel.notice = "nothing to copy yet"
+		return
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		jwtModel.notice = fmt.Sprintf("failed to copy: %v", err)
+		return
+	}
+	jwtModel.notice = "Copied the " + what + " to the clipboard"
+}
+
+func (jwtModel *Model) resize() {
+	// The token or claims take 4 lines, the title, borders, key, notice and help
+	// take 8
+	for _, input := range []*textarea.Model{&jwtModel.token, &jwtModel.claims} {
+		input.SetWidth(max(jwtModel.width-2, 10))
+		input.SetHeight(4)
+	}
+	jwtModel.key.Width = max(jwtModel.width-6, 10)
+	jwtModel.viewport.Width = jwtModel.width
+	jwtModel.viewport.Height = max(jwtModel.height-12, 1)
+	if jwtModel.input().Value() != "" {
+		jwtModel.render()
+	}
+}
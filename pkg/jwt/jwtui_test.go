@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModel_Sign(t *testing.T) {
+	jwtModel := New("monokai")
+	jwtModel.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	jwtModel.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	jwtModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(`{"sub":"42"}`)})
+	jwtModel.Update(tea.KeyMsg{Type: tea.KeyTab})
+	jwtModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s3cret")})
+
+	if jwtModel.key.EchoMode != textinput.EchoPassword {
+		t.Error("the secret is not masked")
+	}
+	if jwtModel.token.Value() != "" {
+		t.Errorf("the claims went to the token input: %q", jwtModel.token.Value())
+	}
+	if _, err := Verify(jwtModel.signed, []Key{SecretKey("s3cret")}); err != nil {
+		t.Fatalf("signed %q, Verify() error = %v", jwtModel.signed, err)
+	}
+	if decoded, _ := Decode(jwtModel.signed); decoded.Algorithm() != "HS256" {
+		t.Errorf("signed with %s, want HS256", decoded.Algorithm())
+	}
+
+	// The next algorithm needs a key the secret is not
+	jwtModel.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if _, err := Verify(jwtModel.signed, []Key{SecretKey("s3cret")}); err != nil || Algorithms[jwtModel.algorithm] != "HS384" {
+		t.Errorf("after ctrl+n signed with %s: %v", Algorithms[jwtModel.algorithm], err)
+	}
+}
+
+func TestModel_KeyFileIsShown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, []byte(`{"keys":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jwtModel := New("monokai")
+	jwtModel.Update(tea.KeyMsg{Type: tea.KeyTab})
+	jwtModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(path)})
+	if jwtModel.key.EchoMode != textinput.EchoNormal {
+		t.Error("the path of a key file is masked")
+	}
+}
@@ -0,0 +1,248 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// Key is a secret, public or private key to verify or sign tokens with.
+type Key struct {
+	// ID is the kid of a key read from a JWKS
+	ID string
+	// Algorithm is the alg a JWKS restricts the key to, "" for any
+	Algorithm string
+	// Value is a []byte secret, an *rsa.PublicKey, *ecdsa.PublicKey or
+	// ed25519.PublicKey, or one of their private keys
+	Value any
+}
+
+// SecretKey returns the key of an HMAC secret.
+func SecretKey(secret string) Key {
+	return Key{Value: []byte(secret)}
+}
+
+// LoadKeys reads the keys of a PEM file or a JWKS file at path.
+func LoadKeys(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	var keys []Key
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		keys, err = ParsePEM(data)
+	} else {
+		keys, err = ParseJWKS(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return keys, nil
+}
+
+// ParsePEM reads the public keys, private keys and certificates of a PEM
+// file.
+func ParsePEM(data []byte) ([]Key, error) {
+	var keys []Key
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		var value any
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			value, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			value, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "PRIVATE KEY":
+			value, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			value, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			value, err = x509.ParseECPrivateKey(block.Bytes)
+		case "CERTIFICATE":
+			var certificate *x509.Certificate
+			certificate, err = x509.ParseCertificate(block.Bytes)
+			if err == nil {
+				value = certificate.PublicKey
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", strings.ToLower(block.Type), err)
+		}
+		keys = append(keys, Key{Value: value})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no key or certificate in the PEM data")
+	}
+	return keys, nil
+}
+
+// jwk is a JSON Web Key, with its fields still base64url encoded.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS reads a JSON Web Key Set, or a single JSON Web Key. Keys used
+// for encryption are left out.
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %v", err)
+	}
+	if set.Keys == nil {
+		var single jwk
+		if err := json.Unmarshal(data, &single); err != nil || single.Kty == "" {
+			return nil, errors.New("invalid JWKS: no keys")
+		}
+		set.Keys = []jwk{single}
+	}
+
+	var keys []Key
+	for i, key := range set.Keys {
+		if key.Use == "enc" {
+			continue
+		}
+		value, err := key.value()
+		if err != nil {
+			name := key.Kid
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return nil, fmt.Errorf("invalid key %s: %v", name, err)
+		}
+		keys = append(keys, Key{ID: key.Kid, Algorithm: key.Alg, Value: value})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys in the JWKS")
+	}
+	return keys, nil
+}
+
+func (key jwk) value() (any, error) {
+	switch key.Kty {
+	case "oct":
+		return decodeField("k", key.K)
+	case "RSA":
+		n, err := decodeField("n", key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeField("e", key.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("the exponent is too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		curves := map[string]struct {
+			curve elliptic.Curve
+			check ecdh.Curve
+		}{
+			"P-256": {elliptic.P256(), ecdh.P256()},
+			"P-384": {elliptic.P384(), ecdh.P384()},
+			"P-521": {elliptic.P521(), ecdh.P521()},
+		}
+		curve, ok := curves[key.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", key.Crv)
+		}
+		x, err := decodeField("x", key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeField("y", key.Y)
+		if err != nil {
+			return nil, err
+		}
+		// ecdh checks that the uncompressed point is on the curve
+		size := (curve.curve.Params().BitSize + 7) / 8
+		if len(x) > size || len(y) > size {
+			return nil, errors.New("the point is not on the curve")
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		copy(point[1+size-len(x):], x)
+		copy(point[1+2*size-len(y):], y)
+		if _, err := curve.check.NewPublicKey(point); err != nil {
+			return nil, errors.New("the point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve.curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if key.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", key.Crv)
+		}
+		x, err := decodeField("x", key.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("an Ed25519 key is 32 bytes")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", key.Kty)
+	}
+}
+
+func decodeField(name string, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return data, nil
+}
+
+// public returns the public half of private keys, and other keys as they
+// are.
+func public(value any) any {
+	switch value := value.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return value.(crypto.Signer).Public()
+	}
+	return value
+}
+
+// private reports whether value can sign, being a secret or a private key.
+func private(value any) bool {
+	switch value.(type) {
+	case []byte, *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return true
+	}
+	return false
+}
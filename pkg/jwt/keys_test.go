@@ -0,0 +1,98 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadKeys(t *testing.T) {
+	rsaKey, ecdsaKey, edKey := generateKeys(t)
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemData := append(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER})...)
+
+	encode := func(data []byte) string { return base64.RawURLEncoding.EncodeToString(data) }
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "alg": "RS256", "n": encode(rsaKey.N.Bytes()), "e": "AQAB"},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecdsaKey.X.Bytes()), "y": encode(ecdsaKey.Y.Bytes())},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": encode(edKey.Public().(ed25519.PublicKey))},
+		{"kty": "oct", "kid": "hmac", "k": encode([]byte("s3cret"))},
+		{"kty": "RSA", "kid": "encryption", "use": "enc"},
+	}})
+	badPoint, _ := json.Marshal(map[string]string{"kty": "EC", "crv": "P-256", "x": encode([]byte{1}), "y": encode([]byte{2})})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantIDs []string
+		wantErr string
+	}{
+		{name: "pem", path: write("keys.pem", pemData), wantIDs: []string{"", ""}},
+		{name: "jwks", path: write("jwks.json", jwks), wantIDs: []string{"rsa", "ec", "ed", "hmac"}},
+		{name: "single jwk", path: write("hmac.json", []byte(`{"kty":"oct","kid":"one","k":"czNjcmV0"}`)), wantIDs: []string{"one"}},
+		{name: "point off the curve", path: write("bad.json", badPoint), wantErr: "not on the curve"},
+		{name: "empty pem", path: write("empty.pem", []byte("-----BEGIN NOTHING-----\n-----END NOTHING-----\n")), wantErr: "no key or certificate"},
+		{name: "not json", path: write("notes.txt", []byte("hello")), wantErr: "invalid JWKS"},
+		{name: "missing", path: filepath.Join(dir, "missing.pem"), wantErr: "failed to read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := LoadKeys(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadKeys() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKeys() error = %v", err)
+			}
+			var ids []string
+			for _, key := range keys {
+				ids = append(ids, key.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") || len(ids) != len(tt.wantIDs) {
+				t.Errorf("LoadKeys() ids = %q, want %q", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	// The JWKS keys verify the tokens of their private keys
+	keys, err := LoadKeys(filepath.Join(dir, "jwks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for alg, private := range map[string]any{"RS256": rsaKey, "ES256": ecdsaKey, "EdDSA": edKey, "HS256": []byte("s3cret")} {
+		token, err := Sign([]byte(`{}`), SignOptions{Algorithm: alg, Key: Key{Value: private}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Verify(token, keys); err != nil {
+			t.Errorf("Verify() of %s with the JWKS error = %v", alg, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithms lists the signing algorithms qcli verifies and signs with.
+var Algorithms = []string{
+	"HS256", "HS384", "HS512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// suits reports whether a key value can be used with alg, keeping HMAC
+// secrets and public keys apart so one cannot pass for the other.
+func suits(value any, alg string) bool {
+	switch public(value).(type) {
+	case []byte:
+		return strings.HasPrefix(alg, "HS")
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return strings.HasPrefix(alg, "ES")
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	}
+	return false
+}
+
+// Verify checks the signature of token with the keys that suit its
+// algorithm, preferring the one whose ID matches its kid. The times of
+// the token are not checked, see Decoded.Valid.
+func Verify(token string, keys []Key) (Key, error) {
+	decoded, err := Decode(token)
+	if err != nil {
+		return Key{}, err
+	}
+	alg := decoded.Algorithm()
+	if gojwt.GetSigningMethod(alg) == nil || alg == "none" {
+		return Key{}, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	kid, _ := decoded.Header["kid"].(string)
+
+	var candidates []Key
+	for _, key := range keys {
+		if key.Algorithm != "" && key.Algorithm != alg || !suits(key.Value, alg) {
+			continue
+		}
+		if kid != "" && key.ID == kid {
+			candidates = []Key{key}
+			break
+		}
+		candidates = append(candidates, key)
+	}
+	if len(candidates) == 0 {
+		return Key{}, fmt.Errorf("none of the %d keys given can verify %s", len(keys), alg)
+	}
+
+	parser := gojwt.NewParser(gojwt.WithValidMethods(Algorithms), gojwt.WithoutClaimsValidation(), gojwt.WithPaddingAllowed())
+	for _, key := range candidates {
+		_, err = parser.Parse(compact(token), func(*gojwt.Token) (any, error) {
+			return public(key.Value), nil
+		})
+		if err == nil {
+			return key, nil
+		}
+	}
+	if errors.Is(err, gojwt.ErrTokenSignatureInvalid) {
+		return Key{}, fmt.Errorf("invalid signature, checked with %d %s key(s)", len(candidates), alg)
+	}
+	return Key{}, fmt.Errorf("failed to verify: %v", err)
+}
+
+// SignOptions control Sign.
+type SignOptions struct {
+	// Algorithm is the alg to sign with, HS256 when empty.
+	Algorithm string
+	// Key is an HMAC secret or a private key.
+	Key Key
+	// KeyID sets the kid header when not empty.
+	KeyID string
+	// ExpiresIn sets iat to Now and exp that long after it when not zero.
+	ExpiresIn time.Duration
+	// Now is the time of iat, time.Now() when zero.
+	Now time.Time
+}
+
+// Sign returns a token holding the claims of a JSON object.
+func Sign(claims []byte, options SignOptions) (string, error) {
+	alg := options.Algorithm
+	if alg == "" {
+		alg = "HS256"
+	}
+	method := gojwt.GetSigningMethod(alg)
+	if method == nil || alg == "none" {
+		return "", fmt.Errorf("unsupported algorithm %q, expected one of %s", alg, strings.Join(Algorithms, ", "))
+	}
+	if !suits(options.Key.Value, alg) {
+		return "", fmt.Errorf("%s needs %s", alg, keyKind(alg))
+	}
+	if !private(options.Key.Value) {
+		return "", fmt.Errorf("%s needs %s, not a public key", alg, keyKind(alg))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(claims))
+	decoder.UseNumber()
+	var mapClaims gojwt.MapClaims
+	if err := decoder.Decode(&mapClaims); err != nil || mapClaims == nil {
+		return "", fmt.Errorf("the claims must be a JSON object")
+	}
+	if options.ExpiresIn != 0 {
+		now := options.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		mapClaims["iat"] = now.Unix()
+		mapClaims["exp"] = now.Add(options.ExpiresIn).Unix()
+	}
+
+	token := gojwt.NewWithClaims(method, mapClaims)
+	if options.KeyID != "" {
+		token.Header["kid"] = options.KeyID
+	}
+	signed, err := token.SignedString(options.Key.Value)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign: %v", err)
+	}
+	return signed, nil
+}
+
+func keyKind(alg string) string {
+	switch {
+	case strings.HasPrefix(alg, "HS"):
+		return "a secret"
+	case strings.HasPrefix(alg, "ES"):
+		return "an ECDSA private key"
+	case alg == "EdDSA":
+		return "an Ed25519 private key"
+	default:
+		return "an RSA private key"
+	}
+}
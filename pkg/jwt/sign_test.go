@@ -0,0 +1,150 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func generateKeys(t *testing.T) (*rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rsaKey, ecdsaKey, edKey
+}
+
+func TestSignAndVerify(t *testing.T) {
+	rsaKey, ecdsaKey, edKey := generateKeys(t)
+	secret := SecretKey("s3cret")
+
+	tests := []struct {
+		alg     string
+		private any
+		verify  []Key
+	}{
+		{alg: "HS256", private: []byte("s3cret"), verify: []Key{secret}},
+		{alg: "HS512", private: []byte("s3cret"), verify: []Key{secret}},
+		{alg: "RS256", private: rsaKey, verify: []Key{{Value: &rsaKey.PublicKey}}},
+		{alg: "PS384", private: rsaKey, verify: []Key{{Value: rsaKey}}},
+		{alg: "ES256", private: ecdsaKey, verify: []Key{secret, {Value: &ecdsaKey.PublicKey}}},
+		{alg: "EdDSA", private: edKey, verify: []Key{{Value: edKey.Public()}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			token, err := Sign([]byte(`{"sub":"42"}`), SignOptions{Algorithm: tt.alg, Key: Key{Value: tt.private}})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if _, err := Verify(token, tt.verify); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+
+			// A changed claim breaks the signature
+			parts := strings.Split(token, ".")
+			parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"43"}`))
+			if _, err := Verify(strings.Join(parts, "."), tt.verify); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+				t.Errorf("Verify() of a tampered token error = %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyKeySelection(t *testing.T) {
+	rsaKey, _, _ := generateKeys(t)
+	other, _, _ := generateKeys(t)
+	token, err := Sign([]byte(`{}`), SignOptions{Algorithm: "RS256", Key: Key{Value: rsaKey}, KeyID: "current"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keys    []Key
+		wantID  string
+		wantErr string
+	}{
+		{name: "kid", keys: []Key{{ID: "old", Value: &other.PublicKey}, {ID: "current", Value: &rsaKey.PublicKey}}, wantID: "current"},
+		{name: "without kid", keys: []Key{{Value: &other.PublicKey}, {Value: &rsaKey.PublicKey}}},
+		{name: "secret for rsa", keys: []Key{SecretKey("s3cret")}, wantErr: "none of the 1 keys given can verify RS256"},
+		{name: "alg restriction", keys: []Key{{Algorithm: "RS512", Value: &rsaKey.PublicKey}}, wantErr: "can verify RS256"},
+		{name: "wrong key", keys: []Key{{ID: "current", Value: &other.PublicKey}}, wantErr: "invalid signature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := Verify(token, tt.keys)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Verify() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || key.ID != tt.wantID {
+				t.Errorf("Verify() = %q, %v, want %q", key.ID, err, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	_, ecdsaKey, _ := generateKeys(t)
+	now := time.Unix(1700000000, 0)
+
+	token, err := Sign([]byte(`{"sub":"42","amount":12345678901234567890}`), SignOptions{Key: SecretKey("s3cret"), KeyID: "k1", ExpiresIn: time.Hour, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Algorithm() != "HS256" || decoded.Header["kid"] != "k1" {
+		t.Errorf("header = %v", decoded.Header)
+	}
+	if decoded.Claims["exp"] != json.Number("1700003600") || decoded.Claims["iat"] != json.Number("1700000000") {
+		t.Errorf("claims = %v", decoded.Claims)
+	}
+	if decoded.Claims["amount"] != json.Number("12345678901234567890") {
+		t.Errorf("amount = %v, want the number unchanged", decoded.Claims["amount"])
+	}
+
+	tests := []struct {
+		name    string
+		claims  string
+		options SignOptions
+		wantErr string
+	}{
+		{name: "claims not an object", claims: `[1]`, options: SignOptions{Key: SecretKey("s")}, wantErr: "must be a JSON object"},
+		{name: "unknown alg", claims: `{}`, options: SignOptions{Algorithm: "HS1", Key: SecretKey("s")}, wantErr: "unsupported algorithm"},
+		{name: "none", claims: `{}`, options: SignOptions{Algorithm: "none", Key: SecretKey("s")}, wantErr: "unsupported algorithm"},
+		{name: "secret for ES256", claims: `{}`, options: SignOptions{Algorithm: "ES256", Key: SecretKey("s")}, wantErr: "ES256 needs an ECDSA private key"},
+		{name: "public key", claims: `{}`, options: SignOptions{Algorithm: "ES256", Key: Key{Value: &ecdsaKey.PublicKey}}, wantErr: "not a public key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sign([]byte(tt.claims), tt.options)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Sign() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
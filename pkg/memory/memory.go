@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/config"
+	"github.com/andreivisan/quantum_cli/pkg/rag"
+	"github.com/andreivisan/quantum_cli/pkg/session"
+)
+
+// maxExchangeChars caps the text kept and embedded per exchange, so a long
+// answer fits the embedding model's context.
+const maxExchangeChars = 4000
+
+// Exchange is a question from a saved session and the answer to it.
+type Exchange struct {
+	SessionID string
+	Title     string
+	// Index is the position of the question in the session's messages.
+	Index     int
+	CreatedAt time.Time
+	Question  string
+	Answer    string
+	Embedding []float32
+}
+
+// Memory holds the embedded exchanges of the saved sessions.
+type Memory struct {
+	// Model is the embedding model of every exchange.
+	Model     string
+	Exchanges []Exchange
+}
+
+// Result is an exchange found by Search with its cosine similarity.
+type Result struct {
+	Exchange
+	Score float32
+}
+
+// DefaultPath returns the memory file in config.DataDir.
+func DefaultPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "memory.index"), nil
+}
+
+// Load reads the memory saved at path. A missing file is an empty memory.
+func Load(path string) (*Memory, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Memory{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory: %v", err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory: %v", err)
+	}
+	memory := new(Memory)
+	if err := gob.NewDecoder(reader).Decode(memory); err != nil {
+		return nil, fmt.Errorf("failed to decode memory: %v", err)
+	}
+	return memory, nil
+}
+
+// Save writes the memory to path, creating its directory if needed.
+func (memory *Memory) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create memory directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "memory.*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save memory: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := gzip.NewWriter(tmp)
+	if err := gob.NewEncoder(writer).Encode(memory); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode memory: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save memory: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save memory: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save memory: %v", err)
+	}
+	return nil
+}
+
+// Sync brings the memory up to date with sessions: exchanges of deleted
+// sessions are forgotten and new ones embedded with model. Switching
+// models re-embeds everything. It returns how many exchanges were added;
+// those embedded before an error are kept. progress, if not nil, is
+// called after each exchange.
+func (memory *Memory) Sync(ctx context.Context, sessions []*session.Session, model string, embed rag.Embedder, progress func(done int, total int)) (int, error) {
+	if memory.Model != model {
+		memory.Model = model
+		memory.Exchanges = nil
+	}
+
+	titles := make(map[string]string, len(sessions))
+	for _, saved := range sessions {
+		titles[saved.ID] = saved.Title
+	}
+	type key struct {
+		sessionID string
+		index     int
+	}
+	known := make(map[key]bool, len(memory.Exchanges))
+	kept := memory.Exchanges[:0]
+	for _, exchange := range memory.Exchanges {
+		title, ok := titles[exchange.SessionID]
+		if !ok {
+			continue
+		}
+		exchange.Title = title
+		kept = append(kept, exchange)
+		known[key{exchange.SessionID, exchange.Index}] = true
+	}
+	memory.Exchanges = kept
+
+	var pending []Exchange
+	for _, saved := range sessions {
+		for _, exchange := range exchanges(saved) {
+			if !known[key{exchange.SessionID, exchange.Index}] {
+				pending = append(pending, exchange)
+			}
+		}
+	}
+	for i, exchange := range pending {
+		embedding, err := embed(ctx, exchange.text())
+		if err != nil {
+			return i, fmt.Errorf("failed to embed session %s: %v", exchange.SessionID, err)
+		}
+		exchange.Embedding = rag.Normalize(embedding)
+		memory.Exchanges = append(memory.Exchanges, exchange)
+		if progress != nil {
+			progress(i+1, len(pending))
+		}
+	}
+	return len(pending), nil
+}
+
+// exchanges pairs the questions of saved with their complete answers.
+func exchanges(saved *session.Session) []Exchange {
+	var found []Exchange
+	for i := 0; i+1 < len(saved.Messages); i++ {
+		question, answer := saved.Messages[i], saved.Messages[i+1]
+		if question.Role != ai.RoleUser || answer.Role != ai.RoleAssistant ||
+			answer.Error != "" || answer.Interrupted || answer.Content == "" {
+			continue
+		}
+		found = append(found, Exchange{
+			SessionID: saved.ID,
+			Title:     saved.Title,
+			Index:     i,
+			CreatedAt: question.CreatedAt,
+			Question:  truncate(question.Content, maxExchangeChars/4),
+			Answer:    truncate(answer.Content, maxExchangeChars-maxExchangeChars/4),
+		})
+	}
+	return found
+}
+
+func (exchange Exchange) text() string {
+	return exchange.Question + "\n\n" + exchange.Answer
+}
+
+// truncate cuts text after at most max bytes, without splitting a rune.
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max] + "…"
+}
+
+// Search returns up to k exchanges with a similarity of at least minScore
+// to the query embedding, best first, leaving out the session exclude.
+func (memory *Memory) Search(query []float32, k int, minScore float32, exclude string) []Result {
+	query = rag.Normalize(query)
+	var results []Result
+	for _, exchange := range memory.Exchanges {
+		if exchange.SessionID == exclude || len(exchange.Embedding) != len(query) {
+			continue
+		}
+		var score float32
+		for i, value := range query {
+			score += value * exchange.Embedding[i]
+		}
+		if score >= minScore {
+			results = append(results, Result{Exchange: exchange, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
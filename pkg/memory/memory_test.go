@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/session"
+)
+
+// topicEmbedder embeds text by counting a few keywords, which is enough to
+// make exchanges on the same topic close.
+func topicEmbedder(ctx context.Context, text string) ([]float32, error) {
+	var embedding []float32
+	for _, keyword := range []string{"retr", "theme", "docker"} {
+		embedding = append(embedding, float32(strings.Count(strings.ToLower(text), keyword)))
+	}
+	return embedding, nil
+}
+
+func testSession(id string, title string, messages ...session.Message) *session.Session {
+	return &session.Session{ID: id, Title: title, Messages: messages}
+}
+
+func user(content string) session.Message {
+	return session.Message{Role: ai.RoleUser, Content: content, CreatedAt: time.Date(2024, 12, 5, 10, 0, 0, 0, time.UTC)}
+}
+
+func assistant(content string) session.Message {
+	return session.Message{Role: ai.RoleAssistant, Content: content}
+}
+
+func TestMemory_Sync(t *testing.T) {
+	sessions := []*session.Session{
+		testSession("1", "Retry policy",
+			user("How many retries?"), assistant("Three retries with backoff."),
+			user("And the theme?"), session.Message{Role: ai.RoleAssistant, Error: "connection refused"},
+			user("Which theme?"), session.Message{Role: ai.RoleAssistant, Content: "Dra", Interrupted: true},
+		),
+		testSession("2", "Docker", user("docker compose?"), assistant("Use docker compose up."), user("unanswered")),
+	}
+
+	memory := &Memory{}
+	var progress []int
+	added, err := memory.Sync(context.Background(), sessions, "nomic-embed-text", topicEmbedder, func(done int, total int) {
+		progress = append(progress, done)
+	})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if added != 2 || len(memory.Exchanges) != 2 || !reflect.DeepEqual(progress, []int{1, 2}) {
+		t.Fatalf("Sync() added %d, exchanges %+v, progress %v, want the 2 complete exchanges", added, memory.Exchanges, progress)
+	}
+	first := memory.Exchanges[0]
+	if first.SessionID != "1" || first.Index != 0 || first.Question != "How many retries?" || first.Answer != "Three retries with backoff." {
+		t.Errorf("Sync() first exchange = %+v", first)
+	}
+	if !reflect.DeepEqual(first.Embedding, []float32{1, 0, 0}) {
+		t.Errorf("Sync() embedding = %v, want it normalized", first.Embedding)
+	}
+
+	// A renamed session keeps its exchanges, a deleted one loses them and
+	// only new exchanges are embedded
+	sessions[0].Title = "Retries"
+	sessions[0].Messages = append(sessions[0].Messages, user("Retry on 429?"), assistant("Yes, retry on 429."))
+	failing := func(ctx context.Context, text string) ([]float32, error) {
+		if strings.Contains(text, "How many") {
+			return nil, errors.New("embedded twice")
+		}
+		return topicEmbedder(ctx, text)
+	}
+	added, err = memory.Sync(context.Background(), sessions[:1], "nomic-embed-text", failing, nil)
+	if err != nil || added != 1 {
+		t.Fatalf("Sync() = %d, %v, want 1 new exchange", added, err)
+	}
+	var got []string
+	for _, exchange := range memory.Exchanges {
+		got = append(got, exchange.Title+": "+exchange.Question)
+	}
+	if want := []string{"Retries: How many retries?", "Retries: Retry on 429?"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sync() exchanges = %v, want %v", got, want)
+	}
+
+	// Another model re-embeds everything
+	added, err = memory.Sync(context.Background(), sessions, "mxbai-embed-large", topicEmbedder, nil)
+	if err != nil || added != 3 || memory.Model != "mxbai-embed-large" {
+		t.Errorf("Sync() = %d, %v, model %s, want 3 exchanges of the new model", added, err, memory.Model)
+	}
+}
+
+func TestMemory_SyncError(t *testing.T) {
+	sessions := []*session.Session{
+		testSession("1", "a", user("retry"), assistant("ok"), user("theme"), assistant("dark")),
+	}
+	calls := 0
+	embed := func(ctx context.Context, text string) ([]float32, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("model not found")
+		}
+		return topicEmbedder(ctx, text)
+	}
+
+	memory := &Memory{}
+	added, err := memory.Sync(context.Background(), sessions, "nomic-embed-text", embed, nil)
+	if err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Fatalf("Sync() error = %v, want the embedding error", err)
+	}
+	if added != 1 || len(memory.Exchanges) != 1 {
+		t.Errorf("Sync() added %d, kept %d, want the exchange embedded before the error", added, len(memory.Exchanges))
+	}
+}
+
+func TestMemory_Search(t *testing.T) {
+	memory := &Memory{Exchanges: []Exchange{
+		{SessionID: "1", Question: "retries", Embedding: []float32{1, 0, 0}},
+		{SessionID: "2", Question: "retries and theme", Embedding: []float32{0.6, 0.8, 0}},
+		{SessionID: "3", Question: "theme", Embedding: []float32{0, 1, 0}},
+		{SessionID: "current", Question: "retries again", Embedding: []float32{1, 0, 0}},
+	}}
+
+	tests := []struct {
+		name     string
+		k        int
+		minScore float32
+		want     []string
+	}{
+		{name: "best first", k: 10, minScore: 0, want: []string{"1", "2", "3"}},
+		{name: "limited to k", k: 1, minScore: 0, want: []string{"1"}},
+		{name: "below the minimum score", k: 10, minScore: 0.5, want: []string{"1", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, result := range memory.Search([]float32{3, 0, 0}, tt.k, tt.minScore, "current") {
+				got = append(got, result.SessionID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemory_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qcli", "memory.index")
+
+	empty, err := Load(path)
+	if err != nil || len(empty.Exchanges) != 0 {
+		t.Fatalf("Load() = %+v, %v, want an empty memory", empty, err)
+	}
+
+	memory := &Memory{Model: "nomic-embed-text", Exchanges: []Exchange{{
+		SessionID: "20241205-101500-3fa2",
+		Title:     "Retry policy",
+		Index:     2,
+		CreatedAt: time.Date(2024, 12, 5, 10, 15, 0, 0, time.UTC),
+		Question:  "How many retries?",
+		Answer:    "Three.",
+		Embedding: []float32{1, 0},
+	}}}
+	if err := memory.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, memory) {
+		t.Errorf("Load() = %+v, want %+v", loaded, memory)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("héllo", 2); got != "h…" {
+		t.Errorf("truncate() = %q, want the rune kept whole", got)
+	}
+	if got := truncate("hello", 5); got != "hello" {
+		t.Errorf("truncate() = %q, want short text unchanged", got)
+	}
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/rag"
+)
+
+const (
+	// DefaultTopK is how many past exchanges are recalled per question.
+	DefaultTopK = 3
+	// DefaultMinScore keeps exchanges that are merely on the same topic
+	// as the question out of the prompt.
+	DefaultMinScore = 0.5
+)
+
+// Retriever recalls past exchanges relevant to a question, see
+// rag.Augment. It can be switched on and off while the chat runs.
+type Retriever struct {
+	// Exclude is the session being continued, whose exchanges are part of
+	// the conversation already.
+	Exclude  string
+	TopK     int
+	MinScore float32
+	// Sync, if not nil, runs before the first recall to bring a memory up
+	// to date that was not synced when the chat started. When it fails,
+	// recalling is switched off and Sync runs again once switched back on.
+	Sync func(ctx context.Context) error
+
+	memory  *Memory
+	embed   rag.Embedder
+	enabled atomic.Bool
+}
+
+// NewRetriever returns a disabled Retriever for memory. embed must use
+// the memory's model.
+func NewRetriever(memory *Memory, embed rag.Embedder) *Retriever {
+	return &Retriever{
+		TopK:     DefaultTopK,
+		MinScore: DefaultMinScore,
+		memory:   memory,
+		embed:    embed,
+	}
+}
+
+// SetEnabled switches recalling on or off. It is safe to call while a
+// question is being answered.
+func (retriever *Retriever) SetEnabled(enabled bool) {
+	retriever.enabled.Store(enabled)
+}
+
+func (retriever *Retriever) Enabled() bool {
+	return retriever.enabled.Load()
+}
+
+func (retriever *Retriever) Retrieve(ctx context.Context, question string) (string, []string, error) {
+	if !retriever.Enabled() {
+		return "", nil, nil
+	}
+	if retriever.Sync != nil {
+		if err := retriever.Sync(ctx); err != nil {
+			// Do not fail every question until the chat ends
+			retriever.SetEnabled(false)
+			return "", nil, fmt.Errorf("memory switched off, failed to sync it: %w", err)
+		}
+		retriever.Sync = nil
+	}
+	if len(retriever.memory.Exchanges) == 0 {
+		return "", nil, nil
+	}
+
+	embedding, err := retriever.embed(ctx, question)
+	if err != nil {
+		return "", nil, err
+	}
+	results := retriever.memory.Search(embedding, retriever.TopK, retriever.MinScore, retriever.Exclude)
+	if len(results) == 0 {
+		return "", nil, nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString("The following exchanges from earlier conversations may be relevant. ")
+	builder.WriteString("Use them only if they help answer the question.\n")
+	var sources []string
+	for _, result := range results {
+		fmt.Fprintf(&builder, "\n[%s · %s]\nUser: %s\nAI: %s\n",
+			result.CreatedAt.Local().Format(time.DateOnly), result.Title, result.Question, result.Answer)
+		source := "session " + result.SessionID
+		if len(sources) == 0 || sources[len(sources)-1] != source {
+			sources = append(sources, source)
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n"), sources, nil
+}
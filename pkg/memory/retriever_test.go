@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetriever(t *testing.T) {
+	memory := &Memory{Model: "nomic-embed-text", Exchanges: []Exchange{
+		{SessionID: "1", Title: "Retry policy", Question: "How many retries?", Answer: "Three.", Embedding: []float32{1, 0, 0},
+			CreatedAt: time.Date(2024, 12, 5, 10, 0, 0, 0, time.Local)},
+		{SessionID: "1", Title: "Retry policy", Question: "Retry on 429?", Answer: "Yes.", Embedding: []float32{1, 0, 0},
+			CreatedAt: time.Date(2024, 12, 5, 10, 5, 0, 0, time.Local)},
+		{SessionID: "2", Title: "Themes", Question: "Which theme?", Answer: "Dracula.", Embedding: []float32{0, 1, 0}},
+	}}
+	retriever := NewRetriever(memory, topicEmbedder)
+
+	found, sources, err := retriever.Retrieve(context.Background(), "the retry policy")
+	if err != nil || found != "" || sources != nil {
+		t.Fatalf("Retrieve() = %q, %v, %v, want nothing while disabled", found, sources, err)
+	}
+
+	synced := 0
+	retriever.Sync = func(ctx context.Context) error { synced++; return nil }
+	retriever.SetEnabled(true)
+	for i := 0; i < 2; i++ {
+		found, sources, err = retriever.Retrieve(context.Background(), "the retry policy")
+		if err != nil {
+			t.Fatalf("Retrieve() error = %v", err)
+		}
+	}
+	if synced != 1 {
+		t.Errorf("Retrieve() synced %d times, want once", synced)
+	}
+	if !strings.Contains(found, "[2024-12-05 · Retry policy]\nUser: How many retries?\nAI: Three.") ||
+		strings.Contains(found, "Dracula") {
+		t.Errorf("Retrieve() = %q, want only the retry exchanges", found)
+	}
+	if len(sources) != 1 || sources[0] != "session 1" {
+		t.Errorf("Retrieve() sources = %v, want [session 1]", sources)
+	}
+
+	retriever.Exclude = "1"
+	if found, _, _ := retriever.Retrieve(context.Background(), "the retry policy"); found != "" {
+		t.Errorf("Retrieve() = %q, want nothing from the excluded session", found)
+	}
+}
+
+func TestRetriever_SyncError(t *testing.T) {
+	retriever := NewRetriever(&Memory{}, topicEmbedder)
+	fail := true
+	retriever.Sync = func(ctx context.Context) error {
+		if fail {
+			return errors.New("model not installed")
+		}
+		return nil
+	}
+	retriever.SetEnabled(true)
+
+	if _, _, err := retriever.Retrieve(context.Background(), "question"); err == nil || !strings.Contains(err.Error(), "model not installed") {
+		t.Fatalf("Retrieve() error = %v, want the sync error", err)
+	}
+	if retriever.Enabled() {
+		t.Error("memory still on after a failed sync")
+	}
+	if _, _, err := retriever.Retrieve(context.Background(), "question"); err != nil {
+		t.Errorf("Retrieve() while switched off error = %v", err)
+	}
+
+	fail = false
+	retriever.SetEnabled(true)
+	if _, _, err := retriever.Retrieve(context.Background(), "question"); err != nil || retriever.Sync != nil {
+		t.Errorf("Retrieve() after switching back on error = %v, want a new sync", err)
+	}
+}
@@ -1,6 +1,9 @@
 package menu
 
 import (
+	"io"
+
+	"github.com/andreivisan/quantum_cli/pkg/tool"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,21 +23,29 @@ var (
 	description = `Quantum CLI (qcli) is a developer's companion providing an interactive terminal interface 
 for various development tools, with AI capabilities powered by Ollama.
 
-Currently available:
-• AI Chat with Chain of Thought reasoning for more detailed responses
+Pick a tool below, or run it directly as a qcli command, see 'qcli --help'.
 
 Coming soon:
-• OCR capabilities
 • Additional developer tools and AI features
 `
 	titleStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("36")).Bold(true)
 	descriptionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("99")).MarginTop(1)
+	noticeStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
 	listStyle        = lipgloss.NewStyle().MarginTop(7)
 )
 
+// ChoiceMsg reports the tool picked in the menu.
+type ChoiceMsg struct {
+	Name string
+}
+
+// NoticeMsg shows a line under the description, such as why a tool could
+// not be opened.
+type NoticeMsg string
+
 type Model struct {
 	list     list.Model
-	choice   string
+	notice   string
 	quitting bool
 	width    int
 	height   int
@@ -42,17 +53,41 @@ type Model struct {
 
 type item struct {
 	title, description string
+	// unavailable is why the tool cannot be picked, "" when it can
+	unavailable string
 }
 
-func (listItem item) Title() string       { return listItem.title }
-func (listItem item) Description() string { return listItem.description }
+func (listItem item) Title() string { return listItem.title }
+func (listItem item) Description() string {
+	if listItem.unavailable != "" {
+		return "unavailable: " + listItem.unavailable
+	}
+	return listItem.description
+}
 func (listItem item) FilterValue() string { return listItem.title }
 
-func New() *Model {
-	items := []list.Item{
-		item{title: "AI chat", description: "chat with AI"},
-		item{title: "AI OCR", description: "COMING SOON: extract text from images"},
+// New returns the main menu listing tools. Tools whose check fails are
+// shown dimmed with the reason and cannot be picked.
+func New(tools []tool.Tool) *Model {
+	items := make([]list.Item, len(tools))
+	for i, tool := range tools {
+		listItem := item{title: tool.Name, description: tool.Description}
+		if err := tool.Unavailable(); err != nil {
+			listItem.unavailable = err.Error()
+		}
+		items[i] = listItem
+	}
+	menuModel := &Model{
+		list: list.New(items, menuDelegate{DefaultDelegate: NewDelegate(), disabled: disabledDelegate()}, 0, 0),
 	}
+	menuModel.list.SetShowTitle(false)
+	menuModel.list.SetShowStatusBar(false)
+	return menuModel
+}
+
+// NewDelegate returns the list delegate shared by every qcli list, with
+// the selected item highlighted in cyberpunk yellow.
+func NewDelegate() list.DefaultDelegate {
 	delegate := list.NewDefaultDelegate()
 	cyberpunkYellow := lipgloss.Color("226")
 
@@ -63,12 +98,36 @@ func New() *Model {
 	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
 		Foreground(cyberpunkYellow).
 		BorderLeftForeground(cyberpunkYellow)
-	menuModel := &Model{
-		list: list.New(items, delegate, 0, 0),
+	return delegate
+}
+
+// menuDelegate renders unavailable tools with the disabled styles.
+type menuDelegate struct {
+	list.DefaultDelegate
+	disabled list.DefaultDelegate
+}
+
+func (delegate menuDelegate) Render(writer io.Writer, listModel list.Model, index int, listItem list.Item) {
+	if menuItem, ok := listItem.(item); ok && menuItem.unavailable != "" {
+		delegate.disabled.Render(writer, listModel, index, listItem)
+		return
 	}
-	menuModel.list.SetShowTitle(false)
-	menuModel.list.SetShowStatusBar(false)
-	return menuModel
+	delegate.DefaultDelegate.Render(writer, listModel, index, listItem)
+}
+
+func disabledDelegate() list.DefaultDelegate {
+	delegate := list.NewDefaultDelegate()
+	dimmed := lipgloss.Color("240")
+
+	delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Foreground(dimmed)
+	delegate.Styles.NormalDesc = delegate.Styles.NormalDesc.Foreground(dimmed)
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(dimmed).
+		BorderLeftForeground(dimmed)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(dimmed).
+		BorderLeftForeground(dimmed)
+	return delegate
 }
 
 func (menuModel Model) Init() tea.Cmd {
@@ -80,11 +139,7 @@ func (menuModel Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.WindowSizeMsg:
 		menuModel.width, menuModel.height = msg.Width, msg.Height
-		headerContent := lipgloss.JoinVertical(lipgloss.Left,
-			titleStyle.Render(asciiArt),
-			descriptionStyle.Render(description),
-		)
-		headerContentHeight := lipgloss.Height(headerContent)
+		headerContentHeight := lipgloss.Height(menuModel.header())
 		listHeight := menuModel.height - headerContentHeight
 		horizontalMargin, verticalMargin := listStyle.GetFrameSize()
 		menuModel.list.SetSize(
@@ -99,22 +154,33 @@ func (menuModel Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return menuModel, tea.Quit
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 			selectedItem, ok := menuModel.list.SelectedItem().(item)
-			if ok {
-				menuModel.choice = selectedItem.title
-				return menuModel, tea.Quit
+			if ok && selectedItem.unavailable == "" {
+				menuModel.notice = ""
+				return menuModel, func() tea.Msg { return ChoiceMsg{Name: selectedItem.title} }
 			}
 		}
+
+	case NoticeMsg:
+		menuModel.notice = string(msg)
+		return menuModel, nil
 	}
 	var cmd tea.Cmd
 	menuModel.list, cmd = menuModel.list.Update(msg)
 	return menuModel, cmd
 }
 
-func (menuModel Model) View() string {
-	header := lipgloss.JoinVertical(lipgloss.Left,
+// header renders the title, the description and the notice line, which
+// is kept even when empty so the list does not move.
+func (menuModel Model) header() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
 		titleStyle.Render(asciiArt),
 		descriptionStyle.Render(description),
+		noticeStyle.Render(menuModel.notice),
 	)
+}
+
+func (menuModel Model) View() string {
+	header := menuModel.header()
 	listView := listStyle.Render(menuModel.list.View())
 	content := lipgloss.JoinVertical(
 		lipgloss.Center,
@@ -130,10 +196,6 @@ func (menuModel Model) View() string {
 	)
 }
 
-func (menuModel Model) Choice() string {
-	return menuModel.choice
-}
-
 func (menuModel Model) Quitting() bool {
 	return menuModel.quitting
 }
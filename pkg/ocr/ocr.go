@@ -0,0 +1,120 @@
+package ocr
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/units"
+)
+
+// MaxImageSize is the largest image sent to the model.
+const MaxImageSize = 20_000_000
+
+// systemPrompt keeps vision models from describing the image instead of
+// transcribing it.
+const systemPrompt = `You are an OCR engine. Transcribe all the text in the image exactly as written.
+Keep the reading order, line breaks and indentation. Render tables as Markdown tables and code as fenced code blocks.
+Do not describe the image, translate, correct or summarise the text. If the image contains no text, reply with nothing.`
+
+const prompt = "Extract the text from this image."
+
+// Image is a PNG or JPEG file to extract text from.
+type Image struct {
+	Path string
+	Data []byte
+}
+
+// LoadImage reads the image at path, rejecting files that are not PNG or
+// JPEG or larger than MaxImageSize.
+func LoadImage(path string) (Image, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if info.IsDir() {
+		return Image{}, fmt.Errorf("%s is a directory", path)
+	}
+	if info.Size() > MaxImageSize {
+		return Image{}, fmt.Errorf("%s is %s, the limit is %s", path, units.FormatSize(info.Size()), units.FormatSize(MaxImageSize))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	switch http.DetectContentType(data) {
+	case "image/png", "image/jpeg":
+		return Image{Path: path, Data: data}, nil
+	default:
+		return Image{}, fmt.Errorf("%s is not a PNG or JPEG image", path)
+	}
+}
+
+// Request asks model to transcribe image.
+func Request(model string, image Image) ai.ChatRequest {
+	return ai.ChatRequest{
+		Model:  model,
+		System: systemPrompt,
+		Messages: []ai.Message{{
+			Role:    ai.RoleUser,
+			Content: prompt,
+			Images:  []string{base64.StdEncoding.EncodeToString(image.Data)},
+		}},
+	}
+}
+
+// Extract streams the text of image, as transcribed by model, into
+// outputChan. Errors reported by the backend are returned too.
+func Extract(ctx context.Context, provider ai.Provider, model string, image Image, outputChan chan<- string) error {
+	events := make(chan ai.Event)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(events)
+		errChan <- provider.Chat(ctx, Request(model, image), events)
+	}()
+
+	var serverErr error
+	for event := range events {
+		switch event.Type {
+		case ai.EventAnswer:
+			select {
+			case outputChan <- event.Text:
+			case <-ctx.Done():
+			}
+		case ai.EventError:
+			serverErr = errors.New(event.Text)
+		}
+	}
+	if err := <-errChan; err != nil {
+		return err
+	}
+	return serverErr
+}
+
+// Expand returns the files matching pattern, or pattern itself when it
+// is not a glob so that LoadImage reports why it cannot be read.
+func Expand(pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %s", pattern)
+	}
+	return matches, nil
+}
+
+// TextPath returns where the text of the image at path is saved: next to
+// it, with a .txt extension.
+func TextPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".txt"
+}
@@ -0,0 +1,192 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+)
+
+func writePNG(t *testing.T, path string) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestLoadImage(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "screenshot.png"))
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	large, err := os.Create(filepath.Join(dir, "large.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := large.Truncate(MaxImageSize + 1); err != nil {
+		t.Fatal(err)
+	}
+	large.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "png", path: "screenshot.png"},
+		{name: "not an image", path: "notes.txt", wantErr: "not a PNG or JPEG image"},
+		{name: "too large", path: "large.png", wantErr: "the limit is 20.0 MB"},
+		{name: "missing", path: "missing.png", wantErr: "failed to read"},
+		{name: "directory", path: ".", wantErr: "is a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := LoadImage(filepath.Join(dir, tt.path))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadImage() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || len(image.Data) == 0 {
+				t.Errorf("LoadImage() = %d bytes, %v", len(image.Data), err)
+			}
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "screenshot.png")
+	data := writePNG(t, path)
+	image, err := LoadImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := Request("llama3.2-vision", image)
+	if request.Model != "llama3.2-vision" || request.System == "" || len(request.Messages) != 1 {
+		t.Fatalf("Request() = %+v, want one message with the OCR system prompt", request)
+	}
+	images := request.Messages[0].Images
+	if len(images) != 1 || images[0] != base64.StdEncoding.EncodeToString(data) {
+		t.Errorf("Request() images = %v, want the base64 PNG", images)
+	}
+}
+
+// fakeProvider replays events.
+type fakeProvider struct {
+	events []ai.Event
+	err    error
+}
+
+func (provider *fakeProvider) Chat(ctx context.Context, request ai.ChatRequest, outputChan chan<- ai.Event) error {
+	for _, event := range provider.events {
+		outputChan <- event
+	}
+	return provider.err
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *fakeProvider
+		want     string
+		wantErr  string
+	}{
+		{
+			name: "text",
+			provider: &fakeProvider{events: []ai.Event{
+				{Type: ai.EventStart},
+				{Type: ai.EventThinking, Text: "a receipt"},
+				{Type: ai.EventAnswer, Text: "TOTAL "},
+				{Type: ai.EventAnswer, Text: "12.50"},
+				{Type: ai.EventDone},
+			}},
+			want: "TOTAL 12.50",
+		},
+		{
+			name:     "server error",
+			provider: &fakeProvider{events: []ai.Event{{Type: ai.EventError, Text: "model does not support images"}}},
+			wantErr:  "model does not support images",
+		},
+		{
+			name:     "connection error",
+			provider: &fakeProvider{err: errors.New("connection refused")},
+			wantErr:  "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			textChan := make(chan string)
+			var got strings.Builder
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for text := range textChan {
+					got.WriteString(text)
+				}
+			}()
+			err := Extract(context.Background(), tt.provider, "llava", Image{Path: "a.png"}, textChan)
+			close(textChan)
+			<-done
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Extract() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.String() != tt.want {
+				t.Errorf("Extract() = %q, %v, want %q", got.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "scans/receipt.jpeg", want: "scans/receipt.txt"},
+		{path: "screenshot.png", want: "screenshot.txt"},
+		{path: "noext", want: "noext.txt"},
+	}
+	for _, tt := range tests {
+		if got := TextPath(tt.path); got != tt.want {
+			t.Errorf("TextPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "a.png"))
+	writePNG(t, filepath.Join(dir, "b.png"))
+
+	if got, err := Expand(filepath.Join(dir, "*.png")); err != nil || len(got) != 2 {
+		t.Errorf("Expand() = %v, %v, want both images", got, err)
+	}
+	if got, err := Expand("missing.png"); err != nil || len(got) != 1 || got[0] != "missing.png" {
+		t.Errorf("Expand() = %v, %v, want the plain path back", got, err)
+	}
+	if _, err := Expand(filepath.Join(dir, "*.jpg")); err == nil {
+		t.Error("Expand() error = nil, want no files match")
+	}
+}
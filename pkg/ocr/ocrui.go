@@ -0,0 +1,329 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TextMsg is a piece of the text being extracted from the current image.
+type TextMsg string
+
+// DoneMsg ends the extraction of the current image, with the error that
+// stopped it, if any.
+type DoneMsg struct {
+	Err error
+}
+
+var (
+	titleStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("36")).Bold(true)
+	headerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Bold(true)
+	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	noticeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
+	inputStyle  = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("36"))
+)
+
+// result is the text extracted from one image.
+type result struct {
+	path string
+	text strings.Builder
+	err  string
+}
+
+// Model reads image paths, extracts their text one image at a time and
+// streams it into a viewport.
+type Model struct {
+	provider  ai.Provider
+	model     string
+	input     textinput.Model
+	viewport  viewport.Model
+	mySpinner spinner.Model
+	results   []*result
+	queue     []Image
+	textChan  chan string
+	errChan   chan error
+	cancel    context.CancelFunc
+	notice    string
+	width     int
+	height    int
+	quitting  bool
+}
+
+// New returns the OCR screen, which transcribes images with model through
+// provider.
+func New(provider ai.Provider, model string) *Model {
+	input := textinput.New()
+	input.Placeholder = "Image paths, e.g. screenshot.png scans/*.jpg"
+	input.Prompt = "▶ "
+	input.Focus()
+
+	mySpinner := spinner.New()
+	mySpinner.Spinner = spinner.Dot
+	mySpinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("36"))
+
+	ocrModel := &Model{
+		provider:  provider,
+		model:     model,
+		input:     input,
+		viewport:  viewport.New(0, 0),
+		mySpinner: mySpinner,
+	}
+	ocrModel.viewport.SetContent(noticeStyle.Render("Type the paths of PNG or JPEG images and press Enter."))
+	return ocrModel
+}
+
+func (ocrModel *Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (ocrModel *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		ocrModel.width, ocrModel.height = msg.Width, msg.Height
+		ocrModel.input.Width = msg.Width - 6
+		ocrModel.resize()
+		return ocrModel, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return ocrModel.quit()
+		case "esc":
+			if ocrModel.running() {
+				ocrModel.queue = nil
+				ocrModel.cancel()
+				return ocrModel, nil
+			}
+			return ocrModel.quit()
+		case "ctrl+y":
+			ocrModel.copyText()
+			return ocrModel, nil
+		case "ctrl+s":
+			ocrModel.saveText()
+			return ocrModel, nil
+		case "pgup", "pgdown", "up", "down":
+			var cmd tea.Cmd
+			ocrModel.viewport, cmd = ocrModel.viewport.Update(msg)
+			return ocrModel, cmd
+		case "enter":
+			if ocrModel.running() {
+				return ocrModel, nil
+			}
+			return ocrModel, ocrModel.start(ocrModel.input.Value())
+		}
+
+	case TextMsg:
+		current := ocrModel.results[len(ocrModel.results)-1]
+		current.text.WriteString(string(msg))
+		ocrModel.render()
+		return ocrModel, listenForText(ocrModel.textChan, ocrModel.errChan)
+
+	case DoneMsg:
+		current := ocrModel.results[len(ocrModel.results)-1]
+		ocrModel.cancel()
+		if msg.Err != nil {
+			current.err = msg.Err.Error()
+		}
+		if len(ocrModel.queue) == 0 {
+			ocrModel.cancel = nil
+			ocrModel.input.Focus()
+			ocrModel.render()
+			return ocrModel, textinput.Blink
+		}
+		return ocrModel, ocrModel.next()
+
+	case spinner.TickMsg:
+		if !ocrModel.running() {
+			return ocrModel, nil
+		}
+		var cmd tea.Cmd
+		ocrModel.mySpinner, cmd = ocrModel.mySpinner.Update(msg)
+		return ocrModel, cmd
+	}
+
+	var cmd tea.Cmd
+	ocrModel.input, cmd = ocrModel.input.Update(msg)
+	return ocrModel, cmd
+}
+
+func (ocrModel *Model) View() string {
+	status := noticeStyle.Render(ocrModel.notice)
+	if ocrModel.running() {
+		current := ocrModel.results[len(ocrModel.results)-1]
+		status = ocrModel.mySpinner.View() + noticeStyle.Render(fmt.Sprintf("Reading %s with %s, %d more queued", current.path, ocrModel.model, len(ocrModel.queue)))
+	}
+	help := noticeStyle.Render("enter: extract • ctrl+y: copy • ctrl+s: save .txt • esc: stop/quit")
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("AI OCR"),
+		ocrModel.viewport.View(),
+		status,
+		inputStyle.Render(ocrModel.input.View()),
+		help,
+	)
+}
+
+// Quitting reports whether the user left the OCR screen.
+func (ocrModel *Model) Quitting() bool {
+	return ocrModel.quitting
+}
+
+func (ocrModel *Model) quit() (tea.Model, tea.Cmd) {
+	if ocrModel.running() {
+		ocrModel.queue = nil
+		ocrModel.cancel()
+	}
+	ocrModel.quitting = true
+	return ocrModel, tea.Quit
+}
+
+func (ocrModel *Model) running() bool {
+	return ocrModel.cancel != nil
+}
+
+// start loads the images at the space separated paths of input, expanding
+// globs, and extracts the text of the first one.
+func (ocrModel *Model) start(input string) tea.Cmd {
+	var paths []string
+	for _, pattern := range strings.Fields(input) {
+		matches, err := Expand(pattern)
+		if err != nil {
+			ocrModel.notice = err.Error()
+			return nil
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	var queue []Image
+	for _, path := range paths {
+		image, err := LoadImage(path)
+		if err != nil {
+			// Keep the input so the path can be fixed
+			ocrModel.notice = err.Error()
+			return nil
+		}
+		queue = append(queue, image)
+	}
+	ocrModel.queue = queue
+	ocrModel.results = nil
+	ocrModel.notice = ""
+	ocrModel.input.Reset()
+	ocrModel.input.Blur()
+	return tea.Batch(ocrModel.next(), ocrModel.mySpinner.Tick)
+}
+
+// next streams the text of the first queued image.
+func (ocrModel *Model) next() tea.Cmd {
+	image := ocrModel.queue[0]
+	ocrModel.queue = ocrModel.queue[1:]
+	ocrModel.results = append(ocrModel.results, &result{path: image.Path})
+	ocrModel.render()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ocrModel.cancel = cancel
+	ocrModel.textChan = make(chan string)
+	ocrModel.errChan = make(chan error, 1)
+	go func(textChan chan string, errChan chan error) {
+		err := Extract(ctx, ocrModel.provider, ocrModel.model, image, textChan)
+		if ctx.Err() != nil {
+			err = nil
+		}
+		errChan <- err
+		close(textChan)
+	}(ocrModel.textChan, ocrModel.errChan)
+	return listenForText(ocrModel.textChan, ocrModel.errChan)
+}
+
+func listenForText(textChan <-chan string, errChan <-chan error) tea.Cmd {
+	return func() tea.Msg {
+		text, ok := <-textChan
+		if !ok {
+			return DoneMsg{Err: <-errChan}
+		}
+		return TextMsg(text)
+	}
+}
+
+// text joins the results, headed by their paths when there are several.
+func (ocrModel *Model) text() string {
+	if len(ocrModel.results) == 1 {
+		return ocrModel.results[0].text.String()
+	}
+	var texts []string
+	for _, result := range ocrModel.results {
+		texts = append(texts, "==> "+result.path+" <==\n"+result.text.String())
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+func (ocrModel *Model) copyText() {
+	if len(ocrModel.results) == 0 || ocrModel.running() {
+		ocrModel.notice = "nothing to copy yet"
+		return
+	}
+	if err := clipboard.WriteAll(ocrModel.text()); err != nil {
+		ocrModel.notice = fmt.Sprintf("failed to copy: %v", err)
+		return
+	}
+	ocrModel.notice = "Copied the text to the clipboard"
+}
+
+// saveText writes the text of every image next to it.
+func (ocrModel *Model) saveText() {
+	if len(ocrModel.results) == 0 || ocrModel.running() {
+		ocrModel.notice = "nothing to save yet"
+		return
+	}
+	var saved []string
+	for _, result := range ocrModel.results {
+		if result.err != "" {
+			continue
+		}
+		path := TextPath(result.path)
+		if err := os.WriteFile(path, []byte(result.text.String()), 0o644); err != nil {
+			ocrModel.notice = fmt.Sprintf("failed to save: %v", err)
+			return
+		}
+		saved = append(saved, path)
+	}
+	ocrModel.notice = "Saved " + strings.Join(saved, ", ")
+}
+
+func (ocrModel *Model) resize() {
+	// Title, status, bordered input and help
+	ocrModel.viewport.Width = ocrModel.width
+	ocrModel.viewport.Height = max(ocrModel.height-6, 1)
+	if len(ocrModel.results) > 0 {
+		ocrModel.render()
+	}
+}
+
+func (ocrModel *Model) render() {
+	var builder strings.Builder
+	for i, result := range ocrModel.results {
+		if i > 0 {
+			builder.WriteString("\n\n")
+		}
+		builder.WriteString(headerStyle.Render(result.path) + "\n")
+		builder.WriteString(result.text.String())
+		if result.err != "" {
+			builder.WriteString("\n" + errorStyle.Render("Error: "+result.err))
+		}
+	}
+	wrapped := lipgloss.NewStyle().Width(ocrModel.viewport.Width).Render(builder.String())
+	ocrModel.viewport.SetContent(wrapped)
+	ocrModel.viewport.GotoBottom()
+}
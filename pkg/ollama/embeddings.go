@@ -0,0 +1,29 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Embed returns the embedding of text computed by model through
+// /api/embeddings.
+func (myChecker *Checker) Embed(ctx context.Context, model string, text string) ([]float32, error) {
+	resp, err := myChecker.postJSON(ctx, "/api/embeddings", map[string]string{"model": model, "prompt": text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed text with %s: %v", model, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Embedding []float32 `json:"embedding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding: %v", err)
+	}
+	if len(result.Embedding) == 0 {
+		return nil, errors.New("empty embedding, is " + model + " an embedding model?")
+	}
+	return result.Embedding, nil
+}
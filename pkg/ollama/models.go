@@ -0,0 +1,217 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Model struct {
+	Name       string       `json:"name"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+type ModelDetails struct {
+	Format            string `json:"format"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// ModelInfo is the response of /api/show.
+type ModelInfo struct {
+	License    string         `json:"license"`
+	Modelfile  string         `json:"modelfile"`
+	Parameters string         `json:"parameters"`
+	Template   string         `json:"template"`
+	Details    ModelDetails   `json:"details"`
+	ModelInfo  map[string]any `json:"model_info"`
+}
+
+// PullProgress is one status update of a streaming /api/pull.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest"`
+	Total     int64  `json:"total"`
+	Completed int64  `json:"completed"`
+	Error     string `json:"error"`
+}
+
+// client bounds connecting to Ollama and waiting for its answer, which
+// may include loading a model. Streamed bodies such as a pull take as long
+// as they take.
+var client = &http.Client{Transport: &http.Transport{
+	Proxy:                 http.ProxyFromEnvironment,
+	DialContext:           (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+	ResponseHeaderTimeout: 2 * time.Minute,
+	MaxIdleConns:          10,
+	IdleConnTimeout:       90 * time.Second,
+}}
+
+// ListModels returns the models installed locally.
+func (myChecker *Checker) ListModels() ([]Model, error) {
+	resp, err := client.Get(myChecker.OllamaURL + "/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %v", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("failed to list models: %v", err)
+	}
+
+	var tags struct {
+		Models []Model `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %v", err)
+	}
+	return tags.Models, nil
+}
+
+// HasModel reports whether name is installed. A name without a tag
+// matches the "latest" tag, as it does for the ollama CLI.
+func (myChecker *Checker) HasModel(name string) (bool, error) {
+	models, err := myChecker.ListModels()
+	if err != nil {
+		return false, err
+	}
+	wanted := normalizeModelName(name)
+	for _, model := range models {
+		if normalizeModelName(model.Name) == wanted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PullModel downloads name and streams status updates into progressChan
+// until the pull finishes or ctx is cancelled. progressChan is not closed.
+func (myChecker *Checker) PullModel(ctx context.Context, name string, progressChan chan<- PullProgress) error {
+	resp, err := myChecker.postJSON(ctx, "/api/pull", map[string]any{"model": name, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var progress PullProgress
+		if err := json.Unmarshal(line, &progress); err != nil {
+			return fmt.Errorf("failed to decode pull status: %v", err)
+		}
+		if progress.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", name, progress.Error)
+		}
+		select {
+		case progressChan <- progress:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to read pull status: %v", err)
+	}
+	return nil
+}
+
+// DeleteModel removes name from the local model store.
+func (myChecker *Checker) DeleteModel(name string) error {
+	body, err := json.Marshal(map[string]string{"model": name})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodDelete, myChecker.OllamaURL+"/api/delete", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to delete %s: %v", name, err)
+	}
+	return nil
+}
+
+// ShowModel returns the details, parameters and template of name.
+func (myChecker *Checker) ShowModel(name string) (*ModelInfo, error) {
+	resp, err := myChecker.postJSON(context.Background(), "/api/show", map[string]string{"model": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to show %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+
+	info := new(ModelInfo)
+	if err := json.NewDecoder(resp.Body).Decode(info); err != nil {
+		return nil, fmt.Errorf("failed to decode model info: %v", err)
+	}
+	return info, nil
+}
+
+// postJSON sends payload to path and returns the response if its status
+// is successful.
+func (myChecker *Checker) postJSON(ctx context.Context, path string, payload any) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, myChecker.OllamaURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// checkResponse turns a non-2xx response into an error carrying the
+// message Ollama put in the body.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var apiError struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &apiError) == nil && apiError.Error != "" {
+		return fmt.Errorf("%s (%s)", apiError.Error, resp.Status)
+	}
+	return fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+func normalizeModelName(name string) string {
+	if !strings.Contains(name, ":") {
+		return name + ":latest"
+	}
+	return name
+}
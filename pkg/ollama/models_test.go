@@ -0,0 +1,182 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newModelsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		if r.Method != http.MethodGet {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Expected JSON body, got error %v", err)
+			}
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"qwq:latest","size":19851349856,"details":{"parameter_size":"32.8B"}},{"name":"llama3.2:1b","size":1321098329}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/pull" && body.Model == "qwq":
+			_, _ = w.Write([]byte("{\"status\":\"pulling manifest\"}\n" +
+				"{\"status\":\"pulling 6e4c\",\"digest\":\"sha256:6e4c\",\"total\":100,\"completed\":40}\n" +
+				"{\"status\":\"success\"}\n"))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/pull":
+			_, _ = w.Write([]byte("{\"status\":\"pulling manifest\"}\n{\"error\":\"pull model manifest: file does not exist\"}\n"))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/delete" && body.Model == "qwq":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/embeddings" && body.Model == "nomic-embed-text":
+			_, _ = w.Write([]byte(`{"embedding":[0.5,-0.25,1]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/embeddings" && body.Model == "qwq":
+			_, _ = w.Write([]byte(`{"embedding":[]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/show" && body.Model == "qwq":
+			_, _ = w.Write([]byte(`{"parameters":"stop \"<|im_end|>\"","template":"{{ .Prompt }}","details":{"family":"qwen2"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model not found"}`))
+		}
+	}))
+}
+
+func TestChecker_ListModels(t *testing.T) {
+	ts := newModelsServer(t)
+	defer ts.Close()
+
+	models, err := NewChecker(ts.URL).ListModels()
+	if err != nil {
+		t.Fatalf("ListModels() unexpected error: %v", err)
+	}
+	if len(models) != 2 || models[0].Name != "qwq:latest" || models[0].Details.ParameterSize != "32.8B" {
+		t.Errorf("ListModels() = %+v", models)
+	}
+}
+
+func TestChecker_HasModel(t *testing.T) {
+	ts := newModelsServer(t)
+	defer ts.Close()
+
+	tests := []struct {
+		name  string
+		model string
+		want  bool
+	}{
+		{name: "implicit latest tag", model: "qwq", want: true},
+		{name: "explicit tag", model: "llama3.2:1b", want: true},
+		{name: "other tag", model: "llama3.2", want: false},
+		{name: "missing", model: "mistral", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewChecker(ts.URL).HasModel(tt.model)
+			if err != nil {
+				t.Fatalf("HasModel() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasModel(%q) = %v, want %v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecker_PullModel(t *testing.T) {
+	ts := newModelsServer(t)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		model      string
+		wantStatus []string
+		wantErr    bool
+	}{
+		{
+			name:       "successful pull",
+			model:      "qwq",
+			wantStatus: []string{"pulling manifest", "pulling 6e4c", "success"},
+		},
+		{
+			name:       "unknown model",
+			model:      "nope",
+			wantStatus: []string{"pulling manifest"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progressChan := make(chan PullProgress, 10)
+			err := NewChecker(ts.URL).PullModel(context.Background(), tt.model, progressChan)
+			close(progressChan)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PullModel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for progress := range progressChan {
+				got = append(got, progress.Status)
+			}
+			if len(got) != len(tt.wantStatus) {
+				t.Fatalf("PullModel() statuses = %v, want %v", got, tt.wantStatus)
+			}
+			for i := range got {
+				if got[i] != tt.wantStatus[i] {
+					t.Errorf("PullModel() statuses = %v, want %v", got, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestChecker_DeleteModel(t *testing.T) {
+	ts := newModelsServer(t)
+	defer ts.Close()
+
+	if err := NewChecker(ts.URL).DeleteModel("qwq"); err != nil {
+		t.Errorf("DeleteModel(qwq) unexpected error: %v", err)
+	}
+	if err := NewChecker(ts.URL).DeleteModel("mistral"); err == nil {
+		t.Error("DeleteModel(mistral) expected an error")
+	}
+}
+
+func TestChecker_ShowModel(t *testing.T) {
+	ts := newModelsServer(t)
+	defer ts.Close()
+
+	info, err := NewChecker(ts.URL).ShowModel("qwq")
+	if err != nil {
+		t.Fatalf("ShowModel() unexpected error: %v", err)
+	}
+	if info.Details.Family != "qwen2" || info.Template != "{{ .Prompt }}" {
+		t.Errorf("ShowModel() = %+v", info)
+	}
+	if _, err := NewChecker(ts.URL).ShowModel("mistral"); err == nil {
+		t.Error("ShowModel(mistral) expected an error")
+	}
+}
+
+func TestChecker_Embed(t *testing.T) {
+	ts := newModelsServer(t)
+	defer ts.Close()
+	checker := NewChecker(ts.URL)
+
+	embedding, err := checker.Embed(context.Background(), "nomic-embed-text", "hello")
+	if err != nil {
+		t.Fatalf("Embed() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(embedding, []float32{0.5, -0.25, 1}) {
+		t.Errorf("Embed() = %v", embedding)
+	}
+	for _, model := range []string{"qwq", "mistral"} {
+		if _, err := checker.Embed(context.Background(), model, "hello"); err == nil {
+			t.Errorf("Embed(%s) expected an error", model)
+		}
+	}
+}
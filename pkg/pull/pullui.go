@@ -0,0 +1,112 @@
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andreivisan/quantum_cli/pkg/ollama"
+	"github.com/andreivisan/quantum_cli/pkg/units"
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ProgressMsg ollama.PullProgress
+
+type DoneMsg struct{}
+
+var (
+	titleStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("36")).Bold(true)
+	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("99"))
+	helpStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+// Model shows the progress of an Ollama model download fed from the
+// status updates of ollama.Checker.PullModel.
+type Model struct {
+	modelName    string
+	progressChan <-chan ollama.PullProgress
+	progressBar  progress.Model
+	status       string
+	completed    int64
+	total        int64
+	done         bool
+	quitting     bool
+}
+
+// New returns a pull screen for modelName. The caller closes progressChan
+// once the pull has finished.
+func New(modelName string, progressChan <-chan ollama.PullProgress) *Model {
+	return &Model{
+		modelName:    modelName,
+		progressChan: progressChan,
+		progressBar:  progress.New(progress.WithGradient("#00af87", "#ffff00")),
+		status:       "starting",
+	}
+}
+
+func (pullModel *Model) Init() tea.Cmd {
+	return listenForProgress(pullModel.progressChan)
+}
+
+func (pullModel *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		pullModel.progressBar.Width = min(msg.Width-4, 80)
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" || msg.String() == "esc" || msg.String() == "q" {
+			pullModel.quitting = true
+			return pullModel, tea.Quit
+		}
+
+	case ProgressMsg:
+		pullModel.status = msg.Status
+		pullModel.completed, pullModel.total = msg.Completed, msg.Total
+		var cmd tea.Cmd
+		if msg.Total > 0 {
+			cmd = pullModel.progressBar.SetPercent(float64(msg.Completed) / float64(msg.Total))
+		}
+		return pullModel, tea.Batch(cmd, listenForProgress(pullModel.progressChan))
+
+	case DoneMsg:
+		pullModel.done = true
+		return pullModel, tea.Quit
+
+	case progress.FrameMsg:
+		progressModel, cmd := pullModel.progressBar.Update(msg)
+		pullModel.progressBar = progressModel.(progress.Model)
+		return pullModel, cmd
+	}
+	return pullModel, nil
+}
+
+func (pullModel *Model) View() string {
+	var builder strings.Builder
+	builder.WriteString(titleStyle.Render("Pulling "+pullModel.modelName) + "\n\n")
+	builder.WriteString(pullModel.progressBar.View() + "\n")
+	status := pullModel.status
+	if pullModel.total > 0 {
+		status = fmt.Sprintf("%s  %s / %s", status, units.FormatSize(pullModel.completed), units.FormatSize(pullModel.total))
+	}
+	builder.WriteString(statusStyle.Render(status) + "\n\n")
+	if !pullModel.done {
+		builder.WriteString(helpStyle.Render("Press q to cancel") + "\n")
+	}
+	return builder.String()
+}
+
+// Quitting reports whether the user cancelled the pull.
+func (pullModel *Model) Quitting() bool {
+	return pullModel.quitting
+}
+
+func listenForProgress(progressChan <-chan ollama.PullProgress) tea.Cmd {
+	return func() tea.Msg {
+		progress, ok := <-progressChan
+		if !ok {
+			return DoneMsg{}
+		}
+		return ProgressMsg(progress)
+	}
+}
@@ -0,0 +1,46 @@
+package pull
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andreivisan/quantum_cli/pkg/ollama"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModel(t *testing.T) {
+	progressChan := make(chan ollama.PullProgress, 1)
+	pullModel := New("qwq", progressChan)
+	pullModel.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+
+	progressChan <- ollama.PullProgress{Status: "pulling 3fa2", Completed: 1_500_000, Total: 3_000_000}
+	msg := pullModel.Init()()
+	if _, ok := msg.(ProgressMsg); !ok {
+		t.Fatalf("Init() command = %T, want a ProgressMsg", msg)
+	}
+	pullModel.Update(msg)
+	if view := pullModel.View(); !strings.Contains(view, "pulling 3fa2  1.5 MB / 3.0 MB") || !strings.Contains(view, "Press q to cancel") {
+		t.Errorf("View() = %q, want the status with sizes", view)
+	}
+
+	close(progressChan)
+	msg = listenForProgress(progressChan)()
+	if _, ok := msg.(DoneMsg); !ok {
+		t.Fatalf("listening on a closed channel = %T, want DoneMsg", msg)
+	}
+	_, cmd := pullModel.Update(msg)
+	if cmd == nil || cmd() != tea.Quit() {
+		t.Error("DoneMsg did not quit")
+	}
+	if pullModel.Quitting() || strings.Contains(pullModel.View(), "Press q") {
+		t.Errorf("finished pull Quitting() = %v, view %q, want done without the cancel hint", pullModel.Quitting(), pullModel.View())
+	}
+}
+
+func TestModel_Cancel(t *testing.T) {
+	pullModel := New("qwq", make(chan ollama.PullProgress))
+	_, cmd := pullModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if cmd == nil || cmd() != tea.Quit() || !pullModel.Quitting() {
+		t.Errorf("q Quitting() = %v, want the pull cancelled", pullModel.Quitting())
+	}
+}
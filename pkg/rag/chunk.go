@@ -0,0 +1,104 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andreivisan/quantum_cli/pkg/attach"
+)
+
+// Chunk is a run of lines of an indexed file.
+type Chunk struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Text      string
+	Embedding []float32
+}
+
+// ChunkOptions controls how files are split.
+type ChunkOptions struct {
+	// Lines is the number of lines per chunk and Overlap how many of them
+	// are repeated from the previous chunk, so code spanning a boundary
+	// is still found.
+	Lines   int
+	Overlap int
+	// MaxChars limits the bytes of a chunk so it fits the embedding
+	// model's context. Chunks of long lines hold fewer lines and a line
+	// longer than that is split over several chunks.
+	MaxChars int
+}
+
+// DefaultChunkOptions suit source code and embedding models with a 2k
+// token context such as nomic-embed-text.
+func DefaultChunkOptions() ChunkOptions {
+	return ChunkOptions{Lines: 40, Overlap: 8, MaxChars: 4000}
+}
+
+// Source returns the chunk's location as path:start-end.
+func (chunk Chunk) Source() string {
+	if chunk.StartLine == chunk.EndLine {
+		return fmt.Sprintf("%s:%d", chunk.Path, chunk.StartLine)
+	}
+	return fmt.Sprintf("%s:%d-%d", chunk.Path, chunk.StartLine, chunk.EndLine)
+}
+
+// embeddingText prefixes the chunk with its path, which often says as
+// much about the code as the code itself.
+func (chunk Chunk) embeddingText() string {
+	return chunk.Path + "\n" + chunk.Text
+}
+
+// SplitFile cuts file into overlapping chunks of lines, skipping chunks
+// with nothing but whitespace.
+func SplitFile(file attach.File, options ChunkOptions) []Chunk {
+	if options.Lines <= 0 || options.Overlap < 0 || options.Overlap >= options.Lines {
+		options = DefaultChunkOptions()
+	}
+	lines := strings.Split(strings.TrimRight(file.Content, "\n"), "\n")
+
+	var chunks []Chunk
+	add := func(start int, end int, text string) {
+		if strings.TrimSpace(text) != "" {
+			chunks = append(chunks, Chunk{Path: file.Path, StartLine: start + 1, EndLine: end, Text: text})
+		}
+	}
+	for start := 0; start < len(lines); {
+		end := start + 1
+		size := len(lines[start])
+		for end < min(start+options.Lines, len(lines)) && (options.MaxChars <= 0 || size+1+len(lines[end]) <= options.MaxChars) {
+			size += 1 + len(lines[end])
+			end++
+		}
+		if options.MaxChars > 0 && size > options.MaxChars {
+			for _, piece := range cut(lines[start], options.MaxChars) {
+				add(start, end, piece)
+			}
+		} else {
+			add(start, end, strings.Join(lines[start:end], "\n"))
+		}
+		if end == len(lines) {
+			break
+		}
+		start = max(end-options.Overlap, start+1)
+	}
+	return chunks
+}
+
+// cut splits text into pieces of at most size bytes, keeping runes whole.
+func cut(text string, size int) []string {
+	var pieces []string
+	for len(text) > size {
+		n := size
+		for n > 0 && !utf8.RuneStart(text[n]) {
+			n--
+		}
+		if n == 0 {
+			_, n = utf8.DecodeRuneInString(text)
+		}
+		pieces = append(pieces, text[:n])
+		text = text[n:]
+	}
+	return append(pieces, text)
+}
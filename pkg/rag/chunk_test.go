@@ -0,0 +1,118 @@
+package rag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreivisan/quantum_cli/pkg/attach"
+)
+
+// numbered returns n lines "1" to "n".
+func numbered(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprint(i + 1)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestSplitFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options ChunkOptions
+		want    [][2]int
+	}{
+		{
+			name:    "single chunk",
+			content: numbered(3),
+			options: ChunkOptions{Lines: 5, Overlap: 1},
+			want:    [][2]int{{1, 3}},
+		},
+		{
+			name:    "overlapping chunks",
+			content: numbered(10),
+			options: ChunkOptions{Lines: 4, Overlap: 1},
+			want:    [][2]int{{1, 4}, {4, 7}, {7, 10}},
+		},
+		{
+			name:    "last chunk is short",
+			content: numbered(9),
+			options: ChunkOptions{Lines: 4, Overlap: 0},
+			want:    [][2]int{{1, 4}, {5, 8}, {9, 9}},
+		},
+		{
+			name:    "blank chunks are skipped",
+			content: "a\n\n\n\n\nb\n",
+			options: ChunkOptions{Lines: 2, Overlap: 0},
+			want:    [][2]int{{1, 2}, {5, 6}},
+		},
+		{
+			name:    "invalid options fall back to the defaults",
+			content: numbered(50),
+			options: ChunkOptions{Lines: 4, Overlap: 4},
+			want:    [][2]int{{1, 40}, {33, 50}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := SplitFile(attach.File{Path: "main.go", Content: tt.content}, tt.options)
+			var got [][2]int
+			for _, chunk := range chunks {
+				if chunk.Path != "main.go" {
+					t.Errorf("SplitFile() chunk path = %q, want main.go", chunk.Path)
+				}
+				got = append(got, [2]int{chunk.StartLine, chunk.EndLine})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitFile() lines = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFile_MaxChars(t *testing.T) {
+	t.Run("long lines make shorter chunks", func(t *testing.T) {
+		line := strings.Repeat("x", 10)
+		file := attach.File{Path: "long.txt", Content: strings.Repeat(line+"\n", 5)}
+		var got [][2]int
+		for _, chunk := range SplitFile(file, ChunkOptions{Lines: 10, Overlap: 1, MaxChars: 25}) {
+			if len(chunk.Text) > 25 {
+				t.Errorf("chunk %s has %d bytes, want at most 25", chunk.Source(), len(chunk.Text))
+			}
+			got = append(got, [2]int{chunk.StartLine, chunk.EndLine})
+		}
+		if want := [][2]int{{1, 2}, {2, 3}, {3, 4}, {4, 5}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitFile() lines = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("a line too long is split", func(t *testing.T) {
+		file := attach.File{Path: "long.txt", Content: "a\n" + strings.Repeat("é", 60) + "\nb\n"}
+		chunks := SplitFile(file, ChunkOptions{Lines: 10, MaxChars: 51})
+		var pieces []string
+		for _, chunk := range chunks {
+			if chunk.StartLine == 2 && chunk.EndLine == 2 {
+				pieces = append(pieces, chunk.Text)
+			}
+		}
+		if want := []string{strings.Repeat("é", 25), strings.Repeat("é", 25), strings.Repeat("é", 10)}; !reflect.DeepEqual(pieces, want) {
+			t.Errorf("SplitFile() pieces of line 2 = %q, want %q", pieces, want)
+		}
+		if last := chunks[len(chunks)-1]; last.Text != "b" || last.StartLine != 3 {
+			t.Errorf("SplitFile() last chunk = %+v, want line 3 kept", last)
+		}
+	})
+}
+
+func TestChunk_Source(t *testing.T) {
+	if got := (Chunk{Path: "a.go", StartLine: 3, EndLine: 9}).Source(); got != "a.go:3-9" {
+		t.Errorf("Source() = %q, want a.go:3-9", got)
+	}
+	if got := (Chunk{Path: "a.go", StartLine: 3, EndLine: 3}).Source(); got != "a.go:3" {
+		t.Errorf("Source() = %q, want a.go:3", got)
+	}
+}
@@ -0,0 +1,269 @@
+package rag
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/andreivisan/quantum_cli/pkg/attach"
+	"github.com/andreivisan/quantum_cli/pkg/config"
+)
+
+// Embedder turns text into an embedding vector, usually through Ollama.
+type Embedder func(ctx context.Context, text string) ([]float32, error)
+
+// Index is an on-disk vector index of the source files of a directory.
+type Index struct {
+	Name string
+	// Root is the absolute path of the indexed directory.
+	Root string
+	// Model is the embedding model, which must also embed the queries.
+	Model     string
+	CreatedAt time.Time
+	Chunks    []Chunk
+}
+
+// Info describes an index without loading its chunks, see Store.Info.
+type Info struct {
+	Name      string
+	Root      string
+	Model     string
+	CreatedAt time.Time
+	// Chunks is the number of chunks.
+	Chunks int
+}
+
+// Result is a chunk found by Search with its cosine similarity.
+type Result struct {
+	Chunk
+	Score float32
+}
+
+// Build chunks files and embeds every chunk. progress, if not nil, is
+// called after each chunk.
+func Build(ctx context.Context, files []attach.File, options ChunkOptions, embed Embedder, progress func(done int, total int)) ([]Chunk, error) {
+	var chunks []Chunk
+	for _, file := range files {
+		chunks = append(chunks, SplitFile(file, options)...)
+	}
+	for i := range chunks {
+		embedding, err := embed(ctx, chunks[i].embeddingText())
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed %s: %v", chunks[i].Source(), err)
+		}
+		chunks[i].Embedding = Normalize(embedding)
+		if progress != nil {
+			progress(i+1, len(chunks))
+		}
+	}
+	return chunks, nil
+}
+
+// Search returns the k chunks most similar to the query embedding, best
+// first.
+func (index *Index) Search(query []float32, k int) []Result {
+	query = Normalize(query)
+	results := make([]Result, 0, len(index.Chunks))
+	for _, chunk := range index.Chunks {
+		if len(chunk.Embedding) != len(query) {
+			continue
+		}
+		var score float32
+		for i, value := range query {
+			score += value * chunk.Embedding[i]
+		}
+		results = append(results, Result{Chunk: chunk, Score: score})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// Normalize scales vector to unit length so a dot product is the cosine
+// similarity.
+func Normalize(vector []float32) []float32 {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	normalized := make([]float32, len(vector))
+	for i, value := range vector {
+		normalized[i] = value / norm
+	}
+	return normalized
+}
+
+// Store keeps one gzipped gob file per index in a directory. A file holds
+// the Info of the index followed by its chunks, so listing the indexes
+// does not decode the embeddings.
+type Store struct {
+	Dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// DefaultDir returns the indexes directory in config.DataDir.
+func DefaultDir() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "indexes"), nil
+}
+
+// ValidateName rejects index names that are not a plain file name, such
+// as "../x" or "/".
+func ValidateName(name string) error {
+	valid := name != "" && name[0] != '.'
+	for _, r := range name {
+		valid = valid && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.')
+	}
+	if !valid {
+		return fmt.Errorf("invalid index name %q, use letters, digits, -, _ or . not at the start", name)
+	}
+	return nil
+}
+
+// Save writes index to disk, replacing any index with the same name.
+func (store *Store) Save(index *Index) error {
+	if err := ValidateName(index.Name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(store.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create indexes directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(store.Dir, index.Name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save index: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := gzip.NewWriter(tmp)
+	encoder := gob.NewEncoder(writer)
+	info := Info{Name: index.Name, Root: index.Root, Model: index.Model, CreatedAt: index.CreatedAt, Chunks: len(index.Chunks)}
+	if err := encoder.Encode(info); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode index: %v", err)
+	}
+	if err := encoder.Encode(index.Chunks); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode index: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save index: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save index: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), store.path(index.Name)); err != nil {
+		return fmt.Errorf("failed to save index: %v", err)
+	}
+	return nil
+}
+
+// Load reads the index called name.
+func (store *Store) Load(name string) (*Index, error) {
+	decoder, info, file, err := store.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	index := &Index{Name: info.Name, Root: info.Root, Model: info.Model, CreatedAt: info.CreatedAt}
+	if err := decoder.Decode(&index.Chunks); err != nil {
+		return nil, fmt.Errorf("failed to decode index %s: %v", name, err)
+	}
+	return index, nil
+}
+
+// Info reads what describes the index called name, leaving out its
+// chunks.
+func (store *Store) Info(name string) (*Info, error) {
+	_, info, file, err := store.open(name)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	return info, nil
+}
+
+// open decodes the Info at the start of the index called name and
+// returns the decoder positioned at its chunks, along with the file to
+// close.
+func (store *Store) open(name string) (*gob.Decoder, *Info, *os.File, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, nil, nil, err
+	}
+	file, err := os.Open(store.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil, fmt.Errorf("index %q not found, create it with 'qcli index <dir> --name %s'", name, name)
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read index %s: %v", name, err)
+	}
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, nil, fmt.Errorf("failed to read index %s: %v", name, err)
+	}
+	decoder := gob.NewDecoder(reader)
+	info := new(Info)
+	if err := decoder.Decode(info); err != nil {
+		file.Close()
+		return nil, nil, nil, fmt.Errorf("failed to decode index %s: %v", name, err)
+	}
+	return decoder, info, file, nil
+}
+
+// List returns the names of the saved indexes in lexical order.
+func (store *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(store.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), indexExt) {
+			names = append(names, strings.TrimSuffix(entry.Name(), indexExt))
+		}
+	}
+	return names, nil
+}
+
+// Delete removes the index called name.
+func (store *Store) Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(store.path(name)); err != nil {
+		return fmt.Errorf("failed to delete index %s: %v", name, err)
+	}
+	return nil
+}
+
+const indexExt = ".index"
+
+func (store *Store) path(name string) string {
+	return filepath.Join(store.Dir, name+indexExt)
+}
@@ -0,0 +1,143 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/attach"
+)
+
+// keywordEmbedder embeds text by counting a few keywords, which is enough
+// to make similar texts close.
+func keywordEmbedder(ctx context.Context, text string) ([]float32, error) {
+	var embedding []float32
+	for _, keyword := range []string{"session", "config", "model"} {
+		embedding = append(embedding, float32(strings.Count(text, keyword)))
+	}
+	return embedding, nil
+}
+
+func TestBuild(t *testing.T) {
+	files := []attach.File{
+		{Path: "session.go", Content: "func saveSession() {}\n"},
+		{Path: "config.go", Content: "func loadConfig() {}\n"},
+	}
+	var progress []int
+	chunks, err := Build(context.Background(), files, DefaultChunkOptions(), keywordEmbedder, func(done int, total int) {
+		progress = append(progress, done)
+		if total != 2 {
+			t.Errorf("Build() progress total = %d, want 2", total)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(chunks) != 2 || !reflect.DeepEqual(progress, []int{1, 2}) {
+		t.Fatalf("Build() = %d chunks, progress %v", len(chunks), progress)
+	}
+	// The path counts towards the embedding and embeddings are normalized
+	if !reflect.DeepEqual(chunks[0].Embedding, []float32{1, 0, 0}) {
+		t.Errorf("Build() embedding = %v, want [1 0 0]", chunks[0].Embedding)
+	}
+
+	failing := func(ctx context.Context, text string) ([]float32, error) { return nil, errors.New("boom") }
+	if _, err := Build(context.Background(), files, DefaultChunkOptions(), failing, nil); err == nil || !strings.Contains(err.Error(), "session.go:1") {
+		t.Errorf("Build() error = %v, want the failing chunk", err)
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := &Index{Chunks: []Chunk{
+		{Path: "a.go", Embedding: Normalize([]float32{1, 0, 0})},
+		{Path: "b.go", Embedding: Normalize([]float32{1, 1, 0})},
+		{Path: "c.go", Embedding: Normalize([]float32{0, 0, 1})},
+		{Path: "other-model.go", Embedding: []float32{1, 0}},
+	}}
+
+	results := index.Search([]float32{2, 0.5, 0}, 2)
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Path)
+	}
+	if !reflect.DeepEqual(got, []string{"a.go", "b.go"}) {
+		t.Errorf("Search() = %v, want [a.go b.go]", got)
+	}
+	if results[0].Score <= results[1].Score || results[0].Score > 1.0001 {
+		t.Errorf("Search() scores = %v, %v", results[0].Score, results[1].Score)
+	}
+
+	if got := index.Search([]float32{0, 0, 1}, 10); len(got) != 3 {
+		t.Errorf("Search() = %d results, want the 3 chunks of the same dimension", len(got))
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if names, err := store.List(); err != nil || len(names) != 0 {
+		t.Fatalf("List() = %v, %v, want nothing", names, err)
+	}
+	if _, err := store.Load("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Load() error = %v, want not found", err)
+	}
+
+	index := &Index{
+		Name:      "qcli",
+		Root:      "/src/qcli",
+		Model:     "nomic-embed-text",
+		CreatedAt: time.Date(2024, 12, 5, 10, 15, 0, 0, time.UTC),
+		Chunks:    []Chunk{{Path: "main.go", StartLine: 1, EndLine: 2, Text: "package main\n", Embedding: []float32{0.6, 0.8}}},
+	}
+	if err := store.Save(index); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save(&Index{Name: "other"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := store.Load("qcli")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, index) {
+		t.Errorf("Load() = %+v, want %+v", loaded, index)
+	}
+	info, err := store.Info("qcli")
+	wantInfo := &Info{Name: "qcli", Root: "/src/qcli", Model: "nomic-embed-text", CreatedAt: index.CreatedAt, Chunks: 1}
+	if err != nil || !reflect.DeepEqual(info, wantInfo) {
+		t.Errorf("Info() = %+v, %v, want %+v", info, err, wantInfo)
+	}
+	if other, err := store.Load("other"); err != nil || other.Name != "other" || len(other.Chunks) != 0 {
+		t.Errorf("Load() of an empty index = %+v, %v", other, err)
+	}
+	if names, err := store.List(); err != nil || !reflect.DeepEqual(names, []string{"other", "qcli"}) {
+		t.Errorf("List() = %v, %v, want [other qcli]", names, err)
+	}
+
+	if err := store.Delete("other"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if names, _ := store.List(); !reflect.DeepEqual(names, []string{"qcli"}) {
+		t.Errorf("List() after Delete() = %v, want [qcli]", names)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for name, wantErr := range map[string]bool{
+		"qcli": false, "quantum_cli-2": false, "my.project": false,
+		"": true, "/": true, "../../x": true, "a/b": true, ".hidden": true, "..": true,
+	} {
+		if err := ValidateName(name); (err != nil) != wantErr {
+			t.Errorf("ValidateName(%q) error = %v, want error %v", name, err, wantErr)
+		}
+	}
+
+	store := NewStore(t.TempDir())
+	if err := store.Save(&Index{Name: "../escape"}); err == nil {
+		t.Error("Save() of ../escape expected an error")
+	}
+}
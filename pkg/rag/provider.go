@@ -0,0 +1,132 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+)
+
+// Retriever finds context relevant to a question. Retrieve returns the
+// text to send ahead of the question, empty when nothing relevant was
+// found, and the sources to cite after the answer.
+type Retriever interface {
+	Retrieve(ctx context.Context, question string) (found string, sources []string, err error)
+}
+
+type augmentedProvider struct {
+	provider   ai.Provider
+	retrievers []Retriever
+}
+
+// Augment wraps provider so every question is sent along with what the
+// retrievers find for it, and the done event of the reply carries their
+// sources. Every retriever sees the question as the user typed it.
+func Augment(provider ai.Provider, retrievers ...Retriever) ai.Provider {
+	return augmentedProvider{provider: provider, retrievers: retrievers}
+}
+
+func (augmented augmentedProvider) Chat(ctx context.Context, request ai.ChatRequest, outputChan chan<- ai.Event) error {
+	last := len(request.Messages) - 1
+	if last < 0 || request.Messages[last].Role != ai.RoleUser {
+		return augmented.provider.Chat(ctx, request, outputChan)
+	}
+
+	question := request.Messages[last].Content
+	var sections, sources []string
+	for _, retriever := range augmented.retrievers {
+		found, cited, err := retriever.Retrieve(ctx, question)
+		if err != nil {
+			return fmt.Errorf("error retrieving context: %w", err)
+		}
+		if found != "" {
+			sections = append(sections, found)
+		}
+		sources = append(sources, cited...)
+	}
+	if len(sections) == 0 {
+		return augmented.provider.Chat(ctx, request, outputChan)
+	}
+
+	// Only the request is augmented, the conversation keeps the question
+	messages := append([]ai.Message{}, request.Messages...)
+	messages[last].Content = strings.Join(sections, "\n\n") + "\n\nQuestion: " + question
+	request.Messages = messages
+
+	events := make(chan ai.Event)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(events)
+		errChan <- augmented.provider.Chat(ctx, request, events)
+	}()
+
+	var emitErr error
+	send := func(event ai.Event) {
+		if emitErr != nil {
+			return
+		}
+		select {
+		case outputChan <- event:
+		case <-ctx.Done():
+			emitErr = ctx.Err()
+		}
+	}
+	for event := range events {
+		if event.Type == ai.EventDone && len(sources) > 0 {
+			event.Sources = sources
+		}
+		send(event)
+	}
+
+	if err := <-errChan; err != nil {
+		return err
+	}
+	return emitErr
+}
+
+// Footer lists sources as a Markdown line to show after an answer.
+func Footer(sources []string) string {
+	locations := make([]string, len(sources))
+	for i, source := range sources {
+		locations[i] = "`" + source + "`"
+	}
+	return "\n\n**Sources:** " + strings.Join(locations, ", ")
+}
+
+type indexRetriever struct {
+	index *Index
+	embed Embedder
+	topK  int
+}
+
+// Retriever returns a Retriever for the topK chunks of index most similar
+// to the question, embedded with embed, which must use index.Model.
+func (index *Index) Retriever(embed Embedder, topK int) Retriever {
+	return indexRetriever{index: index, embed: embed, topK: topK}
+}
+
+func (retriever indexRetriever) Retrieve(ctx context.Context, question string) (string, []string, error) {
+	embedding, err := retriever.embed(ctx, question)
+	if err != nil {
+		return "", nil, err
+	}
+	results := retriever.index.Search(embedding, retriever.topK)
+	if len(results) == 0 {
+		return "", nil, nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Use the following excerpts from the project to answer the question when they are relevant. ")
+	builder.WriteString("Cite the excerpts you rely on by their path and line numbers, for example main.go:12.\n")
+	sources := make([]string, len(results))
+	for i, result := range results {
+		fence := "```"
+		for strings.Contains(result.Text, fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(&builder, "\n[%d] %s\n%s\n%s\n%s\n", i+1, result.Source(), fence, result.Text, fence)
+		sources[i] = result.Source()
+	}
+	return strings.TrimRight(builder.String(), "\n"), sources, nil
+}
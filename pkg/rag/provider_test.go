@@ -0,0 +1,169 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+)
+
+// recordingProvider remembers the request it got and replies with events.
+type recordingProvider struct {
+	request *ai.ChatRequest
+	events  []ai.Event
+}
+
+func (recording recordingProvider) Chat(ctx context.Context, request ai.ChatRequest, outputChan chan<- ai.Event) error {
+	*recording.request = request
+	for _, event := range recording.events {
+		outputChan <- event
+	}
+	return nil
+}
+
+func TestAugment(t *testing.T) {
+	index := &Index{Chunks: []Chunk{
+		{Path: "session.go", StartLine: 10, EndLine: 49, Text: "func Save() {}", Embedding: Normalize([]float32{1, 0, 0})},
+		{Path: "config.go", StartLine: 1, EndLine: 40, Text: "func Load() {}", Embedding: Normalize([]float32{0, 1, 0})},
+	}}
+	var request ai.ChatRequest
+	provider := Augment(recordingProvider{
+		request: &request,
+		events: []ai.Event{
+			{Type: ai.EventStart},
+			{Type: ai.EventAnswer, Text: "In session.go:10."},
+			{Type: ai.EventDone},
+		},
+	}, index.Retriever(keywordEmbedder, 1))
+
+	messages := []ai.Message{
+		{Role: ai.RoleUser, Content: "hi"},
+		{Role: ai.RoleAssistant, Content: "hello"},
+		{Role: ai.RoleUser, Content: "where is the session saved?"},
+	}
+	outputChan := make(chan ai.Event)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- provider.Chat(context.Background(), ai.ChatRequest{Messages: messages}, outputChan)
+		close(outputChan)
+	}()
+	var answer strings.Builder
+	var last ai.Event
+	for event := range outputChan {
+		answer.WriteString(event.Text)
+		last = event
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	question := request.Messages[2].Content
+	if !strings.Contains(question, "[1] session.go:10-49\n```\nfunc Save() {}\n```\n\nQuestion: ") ||
+		!strings.HasSuffix(question, "Question: where is the session saved?") {
+		t.Errorf("Chat() sent question %q, want the session chunk and the question", question)
+	}
+	if strings.Contains(question, "config.go") {
+		t.Errorf("Chat() sent %q, want only the top chunk", question)
+	}
+	if request.Messages[0].Content != "hi" || messages[2].Content != "where is the session saved?" {
+		t.Errorf("Chat() changed the history: %+v", messages)
+	}
+	if answer.String() != "In session.go:10." {
+		t.Errorf("Chat() answer = %q, want the answer without sources", answer.String())
+	}
+	if last.Type != ai.EventDone || !reflect.DeepEqual(last.Sources, []string{"session.go:10-49"}) {
+		t.Errorf("Chat() last event = %v with sources %v, want done with the chunk", last.Type, last.Sources)
+	}
+}
+
+func TestAugment_EmbedError(t *testing.T) {
+	var request ai.ChatRequest
+	failing := func(ctx context.Context, text string) ([]float32, error) { return nil, errors.New("model not found") }
+	provider := Augment(recordingProvider{request: &request}, (&Index{}).Retriever(failing, 3))
+
+	err := provider.Chat(context.Background(), ai.ChatRequest{Messages: []ai.Message{{Role: ai.RoleUser, Content: "hi"}}}, make(chan ai.Event))
+	if err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("Chat() error = %v, want the embedding error", err)
+	}
+}
+
+// staticRetriever finds the same context for every question.
+type staticRetriever struct {
+	found   string
+	sources []string
+}
+
+func (static staticRetriever) Retrieve(ctx context.Context, question string) (string, []string, error) {
+	return static.found, static.sources, nil
+}
+
+func TestAugment_Retrievers(t *testing.T) {
+	tests := []struct {
+		name         string
+		retrievers   []Retriever
+		wantQuestion string
+		wantSources  []string
+	}{
+		{
+			name:         "nothing found",
+			retrievers:   []Retriever{staticRetriever{}},
+			wantQuestion: "why?",
+		},
+		{
+			name: "sections of every retriever",
+			retrievers: []Retriever{
+				staticRetriever{found: "from the project", sources: []string{"a.go:1-2"}},
+				staticRetriever{},
+				staticRetriever{found: "from memory", sources: []string{"session 1"}},
+			},
+			wantQuestion: "from the project\n\nfrom memory\n\nQuestion: why?",
+			wantSources:  []string{"a.go:1-2", "session 1"},
+		},
+		{
+			name:         "context without sources",
+			retrievers:   []Retriever{staticRetriever{found: "from memory"}},
+			wantQuestion: "from memory\n\nQuestion: why?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request ai.ChatRequest
+			provider := Augment(recordingProvider{
+				request: &request,
+				events:  []ai.Event{{Type: ai.EventAnswer, Text: "because"}, {Type: ai.EventDone}},
+			}, tt.retrievers...)
+
+			outputChan := make(chan ai.Event, 3)
+			err := provider.Chat(context.Background(), ai.ChatRequest{Messages: []ai.Message{{Role: ai.RoleUser, Content: "why?"}}}, outputChan)
+			close(outputChan)
+			if err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			var answer strings.Builder
+			var sources []string
+			for event := range outputChan {
+				answer.WriteString(event.Text)
+				sources = append(sources, event.Sources...)
+			}
+			if got := request.Messages[0].Content; got != tt.wantQuestion {
+				t.Errorf("Chat() sent %q, want %q", got, tt.wantQuestion)
+			}
+			if answer.String() != "because" {
+				t.Errorf("Chat() answer = %q, want the answer alone", answer.String())
+			}
+			if !reflect.DeepEqual(sources, tt.wantSources) {
+				t.Errorf("Chat() sources = %v, want %v", sources, tt.wantSources)
+			}
+		})
+	}
+}
+
+func TestFooter(t *testing.T) {
+	if got := Footer([]string{"a.go:1-2", "session 1"}); got != "\n\n**Sources:** `a.go:1-2`, `session 1`" {
+		t.Errorf("Footer() = %q", got)
+	}
+}
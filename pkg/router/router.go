@@ -0,0 +1,178 @@
+package router
+
+import (
+	"reflect"
+
+	"github.com/andreivisan/quantum_cli/pkg/menu"
+	"github.com/andreivisan/quantum_cli/pkg/tool"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// menuScreen is the name of the menu among the screens.
+const menuScreen = ""
+
+// teaPackage is the import path of Bubble Tea, whose own messages drive
+// the program and are passed through untouched.
+var teaPackage = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+
+// screenMsg is a message produced by a command of the named screen, which
+// receives it even when it is not on screen.
+type screenMsg struct {
+	name string
+	msg  tea.Msg
+}
+
+// leaveMsg is a screen quitting. Tools go back to the menu, the menu ends
+// the program.
+type leaveMsg struct {
+	name string
+}
+
+// openedMsg delivers the screen of the tool called name, created off the
+// update loop, or why it could not be created.
+type openedMsg struct {
+	name   string
+	screen tea.Model
+	err    error
+}
+
+// Model hosts the menu and the tool screens. Tools are opened from the
+// menu, keep their state while the user switches between them and go back
+// to the menu when they quit, so they need no changes to be hosted.
+type Model struct {
+	registry *tool.Registry
+	screens  map[string]tea.Model
+	active   string
+	opening  map[string]bool
+	size     tea.WindowSizeMsg
+	quitting bool
+}
+
+// New returns a router showing the menu of the tools in registry.
+func New(registry *tool.Registry) *Model {
+	return &Model{
+		registry: registry,
+		screens:  map[string]tea.Model{menuScreen: menu.New(registry.Tools())},
+		active:   menuScreen,
+		opening:  map[string]bool{},
+	}
+}
+
+func (router *Model) Init() tea.Cmd {
+	return router.wrap(menuScreen, router.screens[menuScreen].Init())
+}
+
+func (router *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case screenMsg:
+		if choice, ok := msg.msg.(menu.ChoiceMsg); ok {
+			return router, router.open(choice.Name)
+		}
+		return router, router.update(msg.name, msg.msg)
+
+	case openedMsg:
+		return router, router.opened(msg)
+
+	case leaveMsg:
+		if msg.name == menuScreen {
+			router.quitting = true
+			return router, tea.Quit
+		}
+		if router.active == msg.name {
+			router.active = menuScreen
+		}
+		return router, nil
+
+	case tea.WindowSizeMsg:
+		// Every screen is resized so it fits when switched to
+		router.size = msg
+		var cmds []tea.Cmd
+		for name := range router.screens {
+			cmds = append(cmds, router.update(name, msg))
+		}
+		return router, tea.Batch(cmds...)
+	}
+	return router, router.update(router.active, msg)
+}
+
+func (router *Model) View() string {
+	return router.screens[router.active].View()
+}
+
+// Quitting reports whether the user quit from the menu.
+func (router *Model) Quitting() bool {
+	return router.quitting
+}
+
+// open shows the tool called name. Its screen is created the first time
+// by a command, so a slow tool does not freeze the menu meanwhile.
+func (router *Model) open(name string) tea.Cmd {
+	if _, ok := router.screens[name]; ok {
+		router.active = name
+		return nil
+	}
+	selected, ok := router.registry.Lookup(name)
+	if !ok || router.opening[name] {
+		return nil
+	}
+	router.opening[name] = true
+	return func() tea.Msg {
+		screen, err := selected.NewModel()
+		return openedMsg{name: name, screen: screen, err: err}
+	}
+}
+
+// opened shows the screen created by open, or the error on the menu.
+func (router *Model) opened(msg openedMsg) tea.Cmd {
+	delete(router.opening, msg.name)
+	if msg.err != nil {
+		return router.update(menuScreen, menu.NoticeMsg(msg.err.Error()))
+	}
+	router.screens[msg.name] = msg.screen
+	router.active = msg.name
+	cmd := router.wrap(msg.name, msg.screen.Init())
+	if router.size.Width > 0 {
+		cmd = tea.Batch(cmd, router.update(msg.name, router.size))
+	}
+	return cmd
+}
+
+// update passes msg to the named screen and tags the messages of the
+// commands it returns with its name.
+func (router *Model) update(name string, msg tea.Msg) tea.Cmd {
+	screen, ok := router.screens[name]
+	if !ok {
+		return nil
+	}
+	screen, cmd := screen.Update(msg)
+	router.screens[name] = screen
+	return router.wrap(name, cmd)
+}
+
+// wrap tags the messages of cmd with the screen called name, so they reach
+// it even after the user switched away, and turns its quitting into
+// leaveMsg.
+func (router *Model) wrap(name string, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		switch msg := msg.(type) {
+		case nil:
+			return nil
+		case tea.QuitMsg:
+			return leaveMsg{name: name}
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, cmd := range msg {
+				cmds[i] = router.wrap(name, cmd)
+			}
+			return cmds
+		}
+		if reflect.TypeOf(msg).PkgPath() == teaPackage {
+			return msg
+		}
+		return screenMsg{name: name, msg: msg}
+	}
+}
@@ -0,0 +1,131 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andreivisan/quantum_cli/pkg/menu"
+	"github.com/andreivisan/quantum_cli/pkg/tool"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type tickMsg struct{}
+
+// fakeScreen records its keys and ticks and quits on esc.
+type fakeScreen struct {
+	keys  []string
+	ticks int
+}
+
+func (screen *fakeScreen) Init() tea.Cmd {
+	return func() tea.Msg { return tickMsg{} }
+}
+
+func (screen *fakeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyEsc {
+			return screen, tea.Quit
+		}
+		screen.keys = append(screen.keys, msg.String())
+	case tickMsg:
+		screen.ticks++
+	}
+	return screen, nil
+}
+
+func (screen *fakeScreen) View() string {
+	return "fake"
+}
+
+// run executes cmd the way Bubble Tea does, feeding its messages back to
+// router, and reports whether the program quit.
+func run(router *Model, cmd tea.Cmd) bool {
+	quit := false
+	for queue := []tea.Cmd{cmd}; len(queue) > 0; {
+		next := queue[0]
+		queue = queue[1:]
+		if next == nil {
+			continue
+		}
+		switch msg := next().(type) {
+		case nil:
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		case tea.QuitMsg:
+			quit = true
+		default:
+			_, cmd := router.Update(msg)
+			queue = append(queue, cmd)
+		}
+	}
+	return quit
+}
+
+func send(router *Model, msg tea.Msg) bool {
+	_, cmd := router.Update(msg)
+	return run(router, cmd)
+}
+
+func TestRouter(t *testing.T) {
+	screen := &fakeScreen{}
+	created := 0
+	registry := &tool.Registry{}
+	registry.Register(tool.Tool{Name: "Fake", NewModel: func() (tea.Model, error) {
+		created++
+		return screen, nil
+	}})
+	registry.Register(tool.Tool{Name: "Broken", NewModel: func() (tea.Model, error) {
+		return nil, errors.New("unknown profile \"x\"")
+	}})
+
+	router := New(registry)
+	run(router, router.Init())
+	send(router, tea.WindowSizeMsg{Width: 100, Height: 60})
+
+	// Picking a tool opens it, creating its screen off the update loop
+	_, cmd := router.Update(screenMsg{name: menuScreen, msg: menu.ChoiceMsg{Name: "Fake"}})
+	if created != 0 || cmd == nil {
+		t.Fatalf("choosing the tool created it %d times in Update, want it left to the command", created)
+	}
+	run(router, cmd)
+	if router.active != "Fake" || router.View() != "fake" || screen.ticks != 1 {
+		t.Fatalf("enter opened %q with %d ticks, want the fake tool initialised", router.active, screen.ticks)
+	}
+	send(router, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+
+	// Esc in the tool goes back to the menu instead of quitting
+	if quit := send(router, tea.KeyMsg{Type: tea.KeyEsc}); quit || router.active != menuScreen {
+		t.Fatalf("esc in the tool quit = %v, active %q, want the menu", quit, router.active)
+	}
+
+	// Messages of the tool still reach it while the menu is shown
+	run(router, router.wrap("Fake", func() tea.Msg { return tickMsg{} }))
+	if screen.ticks != 2 {
+		t.Errorf("ticks = %d, want the tick delivered to the hidden tool", screen.ticks)
+	}
+	send(router, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if len(screen.keys) != 1 {
+		t.Errorf("keys = %v, want menu keys kept from the hidden tool", screen.keys)
+	}
+
+	// A tool that cannot be created leaves a notice in the menu
+	send(router, tea.KeyMsg{Type: tea.KeyEnter})
+	if router.active != menuScreen || !strings.Contains(router.View(), "unknown profile") {
+		t.Errorf("enter on the broken tool opened %q, want the menu with the error", router.active)
+	}
+
+	// Opening the tool again keeps its state
+	send(router, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	send(router, tea.KeyMsg{Type: tea.KeyEnter})
+	if router.active != "Fake" || created != 1 || len(screen.keys) != 1 {
+		t.Errorf("reopened %q, created %d times, keys %v, want the same screen", router.active, created, screen.keys)
+	}
+
+	// Only quitting from the menu ends the program
+	send(router, tea.KeyMsg{Type: tea.KeyEsc})
+	if quit := send(router, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); !quit || !router.Quitting() {
+		t.Errorf("q in the menu quit = %v, Quitting() = %v, want both", quit, router.Quitting())
+	}
+}
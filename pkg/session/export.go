@@ -0,0 +1,223 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatJSON     Format = "json"
+	FormatHTML     Format = "html"
+)
+
+type ExportOptions struct {
+	Format Format
+	// IncludeThinking adds the model's reasoning before each answer.
+	IncludeThinking bool
+}
+
+// ParseFormat accepts md, markdown, json and html.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "json":
+		return FormatJSON, nil
+	case "html":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q, expected md, json or html", name)
+	}
+}
+
+// Export writes session to writer in the requested format, with the title,
+// model and timestamps up front.
+func Export(writer io.Writer, session *Session, options ExportOptions) error {
+	exported := *session
+	if exported.Title == "" {
+		exported.Title = defaultTitle(session.Messages)
+	}
+	exported.Messages = make([]Message, len(session.Messages))
+	for i, message := range session.Messages {
+		if !options.IncludeThinking {
+			message.Thinking = ""
+		}
+		exported.Messages[i] = message
+	}
+
+	switch options.Format {
+	case FormatMarkdown:
+		return exportMarkdown(writer, &exported)
+	case FormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(&exported)
+	case FormatHTML:
+		return exportHTML(writer, &exported)
+	default:
+		return fmt.Errorf("unknown export format %q", options.Format)
+	}
+}
+
+func exportMarkdown(writer io.Writer, session *Session) error {
+	var builder strings.Builder
+	builder.WriteString("---\n")
+	fmt.Fprintf(&builder, "title: %q\n", session.Title)
+	fmt.Fprintf(&builder, "id: %s\n", session.ID)
+	if session.Model != "" {
+		fmt.Fprintf(&builder, "model: %s\n", session.Model)
+	}
+	fmt.Fprintf(&builder, "created: %s\n", session.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&builder, "updated: %s\n", session.UpdatedAt.Format(time.RFC3339))
+	builder.WriteString("---\n\n")
+	fmt.Fprintf(&builder, "# %s\n", session.Title)
+
+	for _, message := range session.Messages {
+		fmt.Fprintf(&builder, "\n## %s · %s\n\n", RoleLabel(message.Role), message.CreatedAt.Format(time.DateTime))
+		if message.Thinking != "" {
+			builder.WriteString("<details>\n<summary>Thinking</summary>\n\n")
+			builder.WriteString(strings.TrimSpace(message.Thinking))
+			builder.WriteString("\n\n</details>\n\n")
+		}
+		builder.WriteString(strings.TrimSpace(message.Content))
+		builder.WriteString("\n")
+		if len(message.Attachments) > 0 {
+			paths := make([]string, len(message.Attachments))
+			for i, file := range message.Attachments {
+				paths[i] = file.Path
+			}
+			fmt.Fprintf(&builder, "\n_Attached: %s_\n", codeList(paths))
+		}
+		if len(message.Sources) > 0 {
+			fmt.Fprintf(&builder, "\n_Sources: %s_\n", codeList(message.Sources))
+		}
+		if message.Interrupted {
+			builder.WriteString("\n_[interrupted]_\n")
+		}
+		if message.Error != "" {
+			fmt.Fprintf(&builder, "\n_[error: %s]_\n", message.Error)
+		}
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+type htmlMessage struct {
+	Role        string
+	Time        string
+	Thinking    template.HTML
+	Content     template.HTML
+	Interrupted bool
+	Error       string
+}
+
+var htmlTemplate = template.Must(template.New("session").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta name="qcli:id" content="{{.ID}}">
+<meta name="qcli:model" content="{{.Model}}">
+<meta name="qcli:created" content="{{.Created}}">
+<meta name="qcli:updated" content="{{.Updated}}">
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 50rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+header p { color: #666; }
+.message { border-left: 3px solid #00af87; padding: 0 1rem; margin: 1.5rem 0; }
+.message.user { border-color: #875fff; }
+.role { font-weight: bold; }
+.time, .interrupted, details { color: #666; }
+.error { color: #d70000; }
+pre { background: #f5f5f5; padding: 0.75rem; overflow-x: auto; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>{{if .Model}}{{.Model}} · {{end}}{{.Created}}</p>
+</header>
+{{range .Messages}}<section class="message {{.Role}}">
+<p><span class="role">{{if eq .Role "assistant"}}AI{{else}}You{{end}}</span> <span class="time">{{.Time}}</span></p>
+{{if .Thinking}}<details><summary>Thinking</summary>
+{{.Thinking}}</details>
+{{end}}{{.Content}}{{if .Interrupted}}<p class="interrupted"><em>[interrupted]</em></p>
+{{end}}{{if .Error}}<p class="error"><em>[error: {{.Error}}]</em></p>
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
+
+func exportHTML(writer io.Writer, session *Session) error {
+	markdown := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	render := func(source string) (template.HTML, error) {
+		var buffer bytes.Buffer
+		// goldmark drops raw HTML by default, so model output cannot inject markup
+		if err := markdown.Convert([]byte(source), &buffer); err != nil {
+			return "", fmt.Errorf("failed to render markdown: %v", err)
+		}
+		return template.HTML(buffer.String()), nil
+	}
+
+	messages := make([]htmlMessage, len(session.Messages))
+	for i, message := range session.Messages {
+		source := message.Content
+		if len(message.Sources) > 0 {
+			source += fmt.Sprintf("\n\n_Sources: %s_", codeList(message.Sources))
+		}
+		content, err := render(source)
+		if err != nil {
+			return err
+		}
+		thinking, err := render(message.Thinking)
+		if err != nil {
+			return err
+		}
+		messages[i] = htmlMessage{
+			Role:        message.Role,
+			Time:        message.CreatedAt.Format(time.DateTime),
+			Thinking:    thinking,
+			Content:     content,
+			Interrupted: message.Interrupted,
+			Error:       message.Error,
+		}
+	}
+
+	return htmlTemplate.Execute(writer, map[string]any{
+		"Title":    session.Title,
+		"ID":       session.ID,
+		"Model":    session.Model,
+		"Created":  session.CreatedAt.Format(time.RFC3339),
+		"Updated":  session.UpdatedAt.Format(time.RFC3339),
+		"Messages": messages,
+	})
+}
+
+// RoleLabel returns the name shown for a message role.
+func RoleLabel(role string) string {
+	if role == ai.RoleAssistant {
+		return "AI"
+	}
+	return "You"
+}
+
+// codeList joins items as inline code, separated by commas.
+func codeList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "`" + item + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
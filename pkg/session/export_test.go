@@ -0,0 +1,115 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+)
+
+func newExportSession() *Session {
+	created := time.Date(2024, 12, 5, 10, 15, 0, 0, time.UTC)
+	return &Session{
+		ID:        "20241205-101500-3fa2",
+		Title:     "Reverse a slice",
+		Model:     "qwq",
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Minute),
+		Messages: []Message{
+			{Role: ai.RoleUser, Content: "How do I reverse a slice?", CreatedAt: created},
+			{
+				Role:      ai.RoleAssistant,
+				Content:   "Use `slices.Reverse`.\n\n<script>alert(1)</script>",
+				Thinking:  "The user wants the standard library helper.",
+				Sources:   []string{"slices.go:10-20"},
+				CreatedAt: created.Add(time.Minute),
+			},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"md": FormatMarkdown, "Markdown": FormatMarkdown, "json": FormatJSON, "html": FormatHTML} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("ParseFormat(pdf) expected an error")
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     ExportOptions
+		wantContain []string
+		wantMissing []string
+	}{
+		{
+			name:    "markdown with front matter",
+			options: ExportOptions{Format: FormatMarkdown},
+			wantContain: []string{
+				"---\ntitle: \"Reverse a slice\"\nid: 20241205-101500-3fa2\nmodel: qwq\ncreated: 2024-12-05T10:15:00Z\n",
+				"## You · 2024-12-05 10:15:00\n\nHow do I reverse a slice?",
+				"## AI · 2024-12-05 10:16:00",
+				"_Sources: `slices.go:10-20`_",
+			},
+			wantMissing: []string{"Thinking"},
+		},
+		{
+			name:        "markdown with thinking",
+			options:     ExportOptions{Format: FormatMarkdown, IncludeThinking: true},
+			wantContain: []string{"<summary>Thinking</summary>\n\nThe user wants the standard library helper."},
+		},
+		{
+			name:        "html",
+			options:     ExportOptions{Format: FormatHTML, IncludeThinking: true},
+			wantContain: []string{"<title>Reverse a slice</title>", `<meta name="qcli:model" content="qwq">`, "<code>slices.Reverse</code>", "<summary>Thinking</summary>", "<em>Sources: <code>slices.go:10-20</code></em>"},
+			wantMissing: []string{"<script>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := Export(&buffer, newExportSession(), tt.options); err != nil {
+				t.Fatalf("Export() unexpected error: %v", err)
+			}
+			got := buffer.String()
+			for _, want := range tt.wantContain {
+				if !strings.Contains(got, want) {
+					t.Errorf("Export() output missing %q:\n%s", want, got)
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Export() output contains %q:\n%s", unwanted, got)
+				}
+			}
+		})
+	}
+}
+
+func TestExport_JSON(t *testing.T) {
+	original := newExportSession()
+
+	var buffer bytes.Buffer
+	if err := Export(&buffer, original, ExportOptions{Format: FormatJSON}); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	var exported Session
+	if err := json.Unmarshal(buffer.Bytes(), &exported); err != nil {
+		t.Fatalf("Export() produced invalid JSON: %v", err)
+	}
+	if exported.Model != "qwq" || len(exported.Messages) != 2 || exported.Messages[1].Thinking != "" {
+		t.Errorf("Export() = %+v, want session without thinking", exported)
+	}
+	if original.Messages[1].Thinking == "" {
+		t.Error("Export() must not modify the session")
+	}
+}
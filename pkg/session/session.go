@@ -0,0 +1,276 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+	"github.com/andreivisan/quantum_cli/pkg/attach"
+	"github.com/andreivisan/quantum_cli/pkg/config"
+)
+
+// Message is a single turn of a saved conversation. AI replies also
+// record the model that wrote them and their usage, when known.
+type Message struct {
+	Role        string    `json:"role"`
+	Content     string    `json:"content"`
+	Thinking    string    `json:"thinking,omitempty"`
+	Interrupted bool      `json:"interrupted,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Usage       *ai.Usage `json:"usage,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	// Sources are what the reply was answered from, see rag.Augment.
+	Sources []string `json:"sources,omitempty"`
+	// Attachments are files sent along with a user message.
+	Attachments []attach.File `json:"attachments,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// Session is a conversation saved to disk.
+type Session struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Messages  []Message `json:"messages"`
+}
+
+// Store keeps one JSON file per session in a directory.
+type Store struct {
+	Dir string
+}
+
+// New starts an empty session with a fresh ID.
+func New(model string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:        newID(now),
+		Model:     model,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Messages:  []Message{},
+	}
+}
+
+// DefaultDir returns the sessions directory in config.DataDir.
+func DefaultDir() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions"), nil
+}
+
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// Save writes session to disk, replacing any previous version. The title
+// defaults to the start of the first user message.
+func (store *Store) Save(session *Session) error {
+	session.UpdatedAt = time.Now()
+	return store.write(session)
+}
+
+// write stores session without touching UpdatedAt.
+func (store *Store) write(session *Session) error {
+	if session.Title == "" {
+		session.Title = defaultTitle(session.Messages)
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %v", err)
+	}
+	if err := os.MkdirAll(store.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %v", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves half a session
+	tmp, err := os.CreateTemp(store.Dir, session.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save session: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save session: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save session: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), store.path(session.ID)); err != nil {
+		return fmt.Errorf("failed to save session: %v", err)
+	}
+	return nil
+}
+
+// Load reads the session with the given ID or unique ID prefix.
+func (store *Store) Load(id string) (*Session, error) {
+	fullID, err := store.resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(store.path(fullID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %v", fullID, err)
+	}
+	session := new(Session)
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("failed to decode session %s: %v", fullID, err)
+	}
+	return session, nil
+}
+
+// SkippedError lists the session files List could not read or decode.
+type SkippedError struct {
+	Errs []error
+}
+
+func (skipped *SkippedError) Error() string {
+	messages := make([]string, len(skipped.Errs))
+	for i, err := range skipped.Errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("skipped %d unreadable sessions: %s", len(skipped.Errs), strings.Join(messages, "; "))
+}
+
+// List returns every saved session, most recently updated first. Files
+// that cannot be read or decoded are skipped and reported by a
+// *SkippedError returned along with the other sessions.
+func (store *Store) List() ([]*Session, error) {
+	ids, err := store.ids()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*Session, 0, len(ids))
+	skipped := &SkippedError{}
+	for _, id := range ids {
+		session, err := store.Load(id)
+		if err != nil {
+			skipped.Errs = append(skipped.Errs, err)
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	if len(skipped.Errs) > 0 {
+		return sessions, skipped
+	}
+	return sessions, nil
+}
+
+// Latest returns the most recently updated session, along with the
+// *SkippedError of List when some files could not be read.
+func (store *Store) Latest() (*Session, error) {
+	sessions, err := store.List()
+	var skipped *SkippedError
+	if err != nil && !errors.As(err, &skipped) {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, errors.New("no saved sessions")
+	}
+	return sessions[0], err
+}
+
+// Delete removes the session with the given ID or unique ID prefix.
+func (store *Store) Delete(id string) error {
+	fullID, err := store.resolve(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(store.path(fullID)); err != nil {
+		return fmt.Errorf("failed to delete session %s: %v", fullID, err)
+	}
+	return nil
+}
+
+// Rename changes the title of the session with the given ID or unique ID
+// prefix. UpdatedAt is kept, so renaming does not reorder the sessions.
+func (store *Store) Rename(id string, title string) error {
+	session, err := store.Load(id)
+	if err != nil {
+		return err
+	}
+	session.Title = title
+	return store.write(session)
+}
+
+func (store *Store) path(id string) string {
+	return filepath.Join(store.Dir, id+".json")
+}
+
+func (store *Store) ids() ([]string, error) {
+	entries, err := os.ReadDir(store.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions directory: %v", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	return ids, nil
+}
+
+func (store *Store) resolve(id string) (string, error) {
+	ids, err := store.ids()
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, candidate := range ids {
+		if candidate == id {
+			return id, nil
+		}
+		if strings.HasPrefix(candidate, id) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("session %q not found", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("session %q is ambiguous: %s", id, strings.Join(matches, ", "))
+	}
+}
+
+// newID returns a session ID that sorts by creation time. Its 32 random
+// bits keep sessions started in the same second from sharing an ID.
+func newID(now time.Time) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+func defaultTitle(messages []Message) string {
+	for _, message := range messages {
+		if message.Role != ai.RoleUser {
+			continue
+		}
+		title := strings.Join(strings.Fields(message.Content), " ")
+		if runes := []rune(title); len(runes) > 60 {
+			title = string(runes[:57]) + "..."
+		}
+		return title
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+)
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/tmp/data")
+
+	got, err := DefaultDir()
+	if err != nil {
+		t.Fatalf("DefaultDir() unexpected error: %v", err)
+	}
+	if want := filepath.Join("/tmp/data", "qcli", "sessions"); got != want {
+		t.Errorf("DefaultDir() = %q, want %q", got, want)
+	}
+}
+
+func TestNewID(t *testing.T) {
+	now := time.Date(2024, 12, 5, 10, 15, 0, 0, time.UTC)
+	first, second := newID(now), newID(now)
+	if !regexp.MustCompile(`^20241205-101500-[0-9a-f]{8}$`).MatchString(first) {
+		t.Errorf("newID() = %q, want the time and 8 hex digits", first)
+	}
+	if first == second {
+		t.Errorf("newID() returned %q twice in the same second", first)
+	}
+}
+
+func TestStore_SaveLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+	session := New("qwq")
+	session.Messages = append(session.Messages,
+		Message{Role: ai.RoleUser, Content: "How do I   reverse\na slice in Go?", CreatedAt: time.Now()},
+		Message{Role: ai.RoleAssistant, Content: "Use slices.Reverse", Interrupted: true, CreatedAt: time.Now()},
+	)
+
+	if err := store.Save(session); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	if session.Title != "How do I reverse a slice in Go?" {
+		t.Errorf("Save() title = %q, want first user message", session.Title)
+	}
+
+	loaded, err := store.Load(session.ID)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if loaded.Model != "qwq" || len(loaded.Messages) != 2 || !loaded.Messages[1].Interrupted {
+		t.Errorf("Load() = %+v, want saved session", loaded)
+	}
+
+	byPrefix, err := store.Load(session.ID[:10])
+	if err != nil || byPrefix.ID != session.ID {
+		t.Errorf("Load(prefix) = %v, %v, want session %s", byPrefix, err, session.ID)
+	}
+}
+
+func TestStore_ListLatestRenameDelete(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if sessions, err := store.List(); err != nil || len(sessions) != 0 {
+		t.Fatalf("List() on empty store = %v, %v", sessions, err)
+	}
+	if _, err := store.Latest(); err == nil {
+		t.Error("Latest() on empty store expected an error")
+	}
+
+	first := New("qwq")
+	first.ID = "20240101-000000-aaaa"
+	second := New("qwq")
+	second.ID = "20240102-000000-bbbb"
+	for _, session := range []*Session{first, second} {
+		if err := store.Save(session); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	latest, err := store.Latest()
+	if err != nil || latest.ID != second.ID {
+		t.Errorf("Latest() = %v, %v, want %s", latest, err, second.ID)
+	}
+
+	if err := store.Rename(first.ID, "Retry policy"); err != nil {
+		t.Fatalf("Rename() unexpected error: %v", err)
+	}
+	sessions, err := store.List()
+	if err != nil || len(sessions) != 2 || sessions[1].Title != "Retry policy" {
+		t.Errorf("List() after rename = %v, %v, want renamed session kept second", sessions, err)
+	}
+	if !sessions[1].UpdatedAt.Equal(first.UpdatedAt) {
+		t.Errorf("Rename() changed UpdatedAt from %v to %v", first.UpdatedAt, sessions[1].UpdatedAt)
+	}
+	latest, err = store.Latest()
+	if err != nil || latest.ID != second.ID {
+		t.Errorf("Latest() after rename = %v, %v, want %s", latest, err, second.ID)
+	}
+
+	if _, err := store.Load("2024"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Load(ambiguous prefix) error = %v, want ambiguous", err)
+	}
+
+	if err := store.Delete(first.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := store.Load(first.ID); err == nil {
+		t.Error("Load() after Delete() expected an error")
+	}
+}
+
+func TestStore_ListSkipsCorruptFiles(t *testing.T) {
+	store := NewStore(t.TempDir())
+	saved := New("qwq")
+	if err := store.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store.Dir, "20240101-000000-dead.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := store.List()
+	var skipped *SkippedError
+	if !errors.As(err, &skipped) || len(skipped.Errs) != 1 || !strings.Contains(err.Error(), "20240101-000000-dead") {
+		t.Errorf("List() error = %v, want the corrupt session reported", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != saved.ID {
+		t.Errorf("List() = %v, want the readable session", sessions)
+	}
+
+	latest, err := store.Latest()
+	if latest == nil || latest.ID != saved.ID || !errors.As(err, &skipped) {
+		t.Errorf("Latest() = %v, %v, want the readable session and the skipped error", latest, err)
+	}
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats aggregates the usage of the AI replies written by one model.
+type Stats struct {
+	Model            string
+	Sessions         int
+	Replies          int
+	PromptTokens     int
+	CompletionTokens int
+	// timed counts the replies with a measured duration, and generated
+	// the tokens and time of those that also have a token count.
+	timed            int
+	timeToFirstToken time.Duration
+	duration         time.Duration
+	generatedTokens  int
+	generation       time.Duration
+}
+
+// AverageTimeToFirstToken is the mean wait for the first token.
+func (stats Stats) AverageTimeToFirstToken() time.Duration {
+	if stats.timed == 0 {
+		return 0
+	}
+	return stats.timeToFirstToken / time.Duration(stats.timed)
+}
+
+// AverageDuration is the mean time a reply took.
+func (stats Stats) AverageDuration() time.Duration {
+	if stats.timed == 0 {
+		return 0
+	}
+	return stats.duration / time.Duration(stats.timed)
+}
+
+// TokensPerSecond is the overall generation speed.
+func (stats Stats) TokensPerSecond() float64 {
+	if stats.generation <= 0 {
+		return 0
+	}
+	return float64(stats.generatedTokens) / stats.generation.Seconds()
+}
+
+// add sums the replies of other into stats. Sessions are not summed as a
+// session may use several models.
+func (stats *Stats) add(other Stats) {
+	stats.Replies += other.Replies
+	stats.PromptTokens += other.PromptTokens
+	stats.CompletionTokens += other.CompletionTokens
+	stats.timed += other.timed
+	stats.timeToFirstToken += other.timeToFirstToken
+	stats.duration += other.duration
+	stats.generatedTokens += other.generatedTokens
+	stats.generation += other.generation
+}
+
+// Aggregate sums the usage of every AI reply in sessions, per model
+// sorted by name and in total. Replies without a model are counted under
+// the session's model.
+func Aggregate(sessions []*Session) (byModel []Stats, total Stats) {
+	models := map[string]*Stats{}
+	for _, session := range sessions {
+		seen := map[string]bool{}
+		for _, message := range session.Messages {
+			if message.Usage == nil {
+				continue
+			}
+			model := message.Model
+			if model == "" {
+				model = session.Model
+			}
+			stats, ok := models[model]
+			if !ok {
+				stats = &Stats{Model: model}
+				models[model] = stats
+			}
+			if !seen[model] {
+				seen[model] = true
+				stats.Sessions++
+			}
+
+			usage := message.Usage
+			stats.Replies++
+			stats.PromptTokens += usage.PromptTokens
+			stats.CompletionTokens += usage.CompletionTokens
+			if usage.Duration > 0 {
+				stats.timed++
+				stats.timeToFirstToken += usage.TimeToFirstToken
+				stats.duration += usage.Duration
+			}
+			if usage.TokensPerSecond() > 0 {
+				stats.generatedTokens += usage.CompletionTokens
+				stats.generation += usage.Duration - usage.TimeToFirstToken
+			}
+		}
+		if len(seen) > 0 {
+			total.Sessions++
+		}
+	}
+
+	for _, stats := range models {
+		byModel = append(byModel, *stats)
+		total.add(*stats)
+	}
+	sort.Slice(byModel, func(i, j int) bool { return byModel[i].Model < byModel[j].Model })
+	return byModel, total
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreivisan/quantum_cli/pkg/ai"
+)
+
+func TestAggregate(t *testing.T) {
+	sessions := []*Session{
+		{
+			Model: "qwq",
+			Messages: []Message{
+				{Role: ai.RoleUser, Content: "Hello"},
+				{Role: ai.RoleAssistant, Content: "Hi", Usage: &ai.Usage{
+					PromptTokens: 10, CompletionTokens: 20,
+					TimeToFirstToken: time.Second, Duration: 3 * time.Second,
+				}},
+				{Role: ai.RoleUser, Content: "Again"},
+				{Role: ai.RoleAssistant, Content: "Hi again", Model: "llama3.2", Usage: &ai.Usage{
+					PromptTokens: 30, CompletionTokens: 40,
+					TimeToFirstToken: 2 * time.Second, Duration: 4 * time.Second,
+				}},
+			},
+		},
+		{
+			Model: "qwq",
+			Messages: []Message{
+				{Role: ai.RoleUser, Content: "Hello"},
+				// Timed, but the backend did not count tokens
+				{Role: ai.RoleAssistant, Content: "Hi", Usage: &ai.Usage{
+					TimeToFirstToken: 3 * time.Second, Duration: 5 * time.Second,
+				}},
+			},
+		},
+		{
+			Model:    "qwq",
+			Messages: []Message{{Role: ai.RoleUser, Content: "No usage"}, {Role: ai.RoleAssistant, Content: "Old reply"}},
+		},
+	}
+
+	byModel, total := Aggregate(sessions)
+
+	if len(byModel) != 2 || byModel[0].Model != "llama3.2" || byModel[1].Model != "qwq" {
+		t.Fatalf("Aggregate() models = %+v, want llama3.2 and qwq", byModel)
+	}
+	qwq := byModel[1]
+	if qwq.Sessions != 2 || qwq.Replies != 2 || qwq.PromptTokens != 10 || qwq.CompletionTokens != 20 {
+		t.Errorf("qwq stats = %+v", qwq)
+	}
+	if got := qwq.AverageTimeToFirstToken(); got != 2*time.Second {
+		t.Errorf("qwq AverageTimeToFirstToken() = %v, want 2s", got)
+	}
+	if got := qwq.AverageDuration(); got != 4*time.Second {
+		t.Errorf("qwq AverageDuration() = %v, want 4s", got)
+	}
+	// Only the reply with a token count contributes to the speed
+	if got := qwq.TokensPerSecond(); got != 10 {
+		t.Errorf("qwq TokensPerSecond() = %v, want 10", got)
+	}
+
+	if total.Sessions != 2 || total.Replies != 3 || total.PromptTokens != 40 || total.CompletionTokens != 60 {
+		t.Errorf("total stats = %+v", total)
+	}
+	if got := total.TokensPerSecond(); got != 15 {
+		t.Errorf("total TokensPerSecond() = %v, want 15", got)
+	}
+}
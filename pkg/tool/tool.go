@@ -0,0 +1,69 @@
+package tool
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// Tool is a screen of the main menu that is also a qcli command.
+type Tool struct {
+	// Name is the title of the tool in the menu.
+	Name        string
+	Description string
+	// Command runs the tool from the command line.
+	Command *cobra.Command
+	// NewModel returns the screen the menu opens for the tool.
+	NewModel func() (tea.Model, error)
+	// Check returns why the tool cannot be used right now, nil when it
+	// can. Tools without a check are always available.
+	Check func() error
+}
+
+// Unavailable returns the reason from Check, nil when the tool can be used.
+func (tool Tool) Unavailable() error {
+	if tool.Check == nil {
+		return nil
+	}
+	return tool.Check()
+}
+
+// Registry holds the tools in the order they were registered, which is
+// the order of the menu.
+type Registry struct {
+	tools []Tool
+}
+
+// Register adds tool. Registering a name twice is a programming error and
+// panics.
+func (registry *Registry) Register(tool Tool) {
+	if _, ok := registry.Lookup(tool.Name); ok {
+		panic(fmt.Sprintf("tool %q registered twice", tool.Name))
+	}
+	registry.tools = append(registry.tools, tool)
+}
+
+// Tools returns the registered tools.
+func (registry *Registry) Tools() []Tool {
+	return append([]Tool{}, registry.tools...)
+}
+
+// Lookup returns the tool called name.
+func (registry *Registry) Lookup(name string) (Tool, bool) {
+	for _, tool := range registry.tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// AddCommands adds the command of every tool to root.
+func (registry *Registry) AddCommands(root *cobra.Command) {
+	for _, tool := range registry.tools {
+		if tool.Command != nil {
+			root.AddCommand(tool.Command)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package tool
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := &Registry{}
+	registry.Register(Tool{Name: "AI chat", Command: &cobra.Command{Use: "chat"}})
+	registry.Register(Tool{Name: "AI OCR", Command: &cobra.Command{Use: "ocr"}})
+	registry.Register(Tool{Name: "Menu only"})
+
+	var names []string
+	for _, tool := range registry.Tools() {
+		names = append(names, tool.Name)
+	}
+	if want := []string{"AI chat", "AI OCR", "Menu only"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Tools() = %v, want %v in registration order", names, want)
+	}
+
+	if tool, ok := registry.Lookup("AI OCR"); !ok || tool.Command.Use != "ocr" {
+		t.Errorf("Lookup() = %+v, %v, want the OCR tool", tool, ok)
+	}
+	if _, ok := registry.Lookup("AI OCR "); ok {
+		t.Error("Lookup() found a tool that was not registered")
+	}
+
+	root := &cobra.Command{Use: "qcli"}
+	registry.AddCommands(root)
+	var commands []string
+	for _, command := range root.Commands() {
+		commands = append(commands, command.Name())
+	}
+	if want := []string{"chat", "ocr"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("AddCommands() added %v, want %v", commands, want)
+	}
+}
+
+func TestRegistry_RegisterTwice(t *testing.T) {
+	registry := &Registry{}
+	registry.Register(Tool{Name: "AI chat"})
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() did not panic on a duplicate name")
+		}
+	}()
+	registry.Register(Tool{Name: "AI chat"})
+}
+
+func TestTool_Unavailable(t *testing.T) {
+	missing := errors.New("llama3.2-vision is not installed")
+	tests := []struct {
+		name string
+		tool Tool
+		want error
+	}{
+		{name: "no check", tool: Tool{Name: "AI chat"}},
+		{name: "passing check", tool: Tool{Name: "AI chat", Check: func() error { return nil }}},
+		{name: "failing check", tool: Tool{Name: "AI OCR", Check: func() error { return missing }}, want: missing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tool.Unavailable(); got != tt.want {
+				t.Errorf("Unavailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package units
+
+import "testing"
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{bytes: 0, want: "0 B"},
+		{bytes: 999, want: "999 B"},
+		{bytes: 1000, want: "1.0 kB"},
+		{bytes: 1536, want: "1.5 kB"},
+		{bytes: 4_700_000_000, want: "4.7 GB"},
+		{bytes: 9_000_000_000_000_000_000, want: "9.0 EB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSize(tt.bytes); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}